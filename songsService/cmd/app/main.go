@@ -13,15 +13,39 @@ import (
 	"os/signal"
 	_ "rest/docs"
 	"rest/internal/cache"
+	"rest/internal/cache/lru"
+	"rest/internal/cache/noop"
 	rd "rest/internal/cache/redis"
+	"rest/internal/cache/tiered"
+	"rest/internal/clients/info"
 	"rest/internal/config"
+	"rest/internal/importer"
+	"rest/internal/jobs"
 	"rest/internal/logger"
+	albumAdd "rest/internal/server/handlers/albums/add"
+	"rest/internal/server/handlers/albums/attach"
+	albumGet "rest/internal/server/handlers/albums/get"
+	"rest/internal/server/handlers/albums/reorder"
+	artistGet "rest/internal/server/handlers/artists/get"
+	"rest/internal/server/handlers/artists/list"
+	"rest/internal/server/handlers/artists/merge"
+	"rest/internal/server/handlers/artists/rename"
+	"rest/internal/server/handlers/diagnostics"
+	jobGet "rest/internal/server/handlers/jobs/get"
 	"rest/internal/server/handlers/songs/add"
+	"rest/internal/server/handlers/songs/export"
 	"rest/internal/server/handlers/songs/get"
+	"rest/internal/server/handlers/songs/imports"
+	"rest/internal/server/handlers/songs/patch"
+	"rest/internal/server/handlers/songs/replace"
 	"rest/internal/server/handlers/songs/rm"
+	"rest/internal/server/handlers/songs/search"
+	"rest/internal/server/handlers/songs/single"
 	"rest/internal/server/handlers/songs/update"
 	"rest/internal/server/handlers/songs/verses"
 	"rest/internal/server/middlewares"
+	"rest/internal/server/middlewares/idempotency"
+	"rest/internal/storage/cached"
 	"rest/internal/storage/postgres"
 	"syscall"
 	"time"
@@ -39,6 +63,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	// the default logger is used by code without a logger of its own
+	slog.SetDefault(log)
+
 	log.Info("app was started", slog.String("op", op), slog.Any("config", cfg))
 	ctx := context.Background()
 
@@ -60,24 +87,60 @@ func main() {
 	log.Info("database was initialized", slog.String("op", op))
 
 	// init cache
-	rdCache, err := rd.New(ctx, cfg.Cache.Address)
+	songsCache, err := newCache(ctx, log, cfg.Cache)
 	if err != nil {
-		log.Error("failed to connect to cache",
+		log.Error("failed to init cache",
 			slog.String("op", op),
 			slog.String("error", err.Error()),
 		)
 		os.Exit(1)
 	}
-	log.Info("cache was initialized", slog.String("op", op))
+	log.Info("cache was initialized", slog.String("op", op), slog.String("mode", cfg.Cache.Mode))
+
+	// songs are cached on first read, warmup only saves the first misses and does not delay the start
+	if cfg.Cache.WarmupSize > 0 {
+		go func() {
+			if err := cache.Warmup(ctx, log, songsCache, db, cfg.Cache.WarmupSize); err != nil {
+				log.Error("failed to warm up cache",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				return
+			}
+			log.Info("cache was warmed up", slog.String("op", op))
+		}()
+	}
 
-	if err = cache.Load(ctx, rdCache, db); err != nil {
-		log.Error("failed to load data to cache",
+	// mutations of songs go through the store which keeps cache consistent
+	store := cached.New(log, db, songsCache)
+
+	// init external API client
+	infoClient, err := info.New(cfg.ExternalAPI.URL, info.Options{
+		Timeout:          cfg.ExternalAPI.Timeout,
+		Retries:          cfg.ExternalAPI.Retries,
+		RetryMinDelay:    cfg.ExternalAPI.RetryMinDelay,
+		RetryMaxDelay:    cfg.ExternalAPI.RetryMaxDelay,
+		BreakerThreshold: cfg.ExternalAPI.BreakerThreshold,
+		BreakerCooldown:  cfg.ExternalAPI.BreakerCooldown,
+	})
+	if err != nil {
+		log.Error("failed to init external API client",
 			slog.String("op", op),
 			slog.String("error", err.Error()),
 		)
 		os.Exit(1)
 	}
-	log.Info("data from database was loaded to cache", slog.String("op", op))
+
+	// init background jobs
+	jobsPool := jobs.New(log, store, infoClient, jobs.Options{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		Lease:        cfg.Jobs.Lease,
+		MinBackoff:   cfg.Jobs.MinBackoff,
+		MaxBackoff:   cfg.Jobs.MaxBackoff,
+	})
+	jobsPool.Start(ctx)
+	log.Info("job workers were started", slog.String("op", op), slog.Int("workers", cfg.Jobs.Workers))
 
 	// init router
 	router := chi.NewRouter()
@@ -88,10 +151,30 @@ func main() {
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 
 	router.Get("/songs", get.New(ctx, log, db))
-	router.Get("/verses", verses.New(ctx, log, db, rdCache))
-	router.Post("/songs", add.New(ctx, log, db, rdCache))
-	router.Put("/songs", update.New(ctx, log, db, rdCache))
-	router.Delete("/songs/{id}", rm.New(ctx, log, db, rdCache))
+	router.Get("/songs/search", search.New(ctx, log, db))
+	router.Get("/songs/export", export.New(ctx, log, db))
+	router.Get("/songs/{id}", single.New(ctx, log, db, songsCache))
+	router.Get("/verses", verses.New(ctx, log, db, songsCache))
+	router.With(idempotency.New(log, db)).Post("/songs", add.New(ctx, log, store, infoClient, cfg.ExternalAPI.DegradedMode))
+	router.Post("/songs/import", imports.New(ctx, log,
+		importer.New(log, store, infoClient, importer.Options{})))
+	router.Put("/songs", update.New(ctx, log, store))
+	router.Put("/songs/{id}", replace.New(ctx, log, store))
+	router.Patch("/songs/{id}", patch.New(ctx, log, store))
+	router.Delete("/songs/{id}", rm.New(ctx, log, store))
+
+	router.Get("/jobs/{id}", jobGet.New(ctx, log, db))
+	router.Get("/diagnostics", diagnostics.New(ctx, log, infoClient))
+
+	router.Get("/artists", list.New(ctx, log, db))
+	router.Get("/artists/{id}", artistGet.New(ctx, log, db))
+	router.Put("/artists/{id}", rename.New(ctx, log, store))
+	router.Post("/artists/{id}/merge", merge.New(ctx, log, store))
+
+	router.Post("/albums", albumAdd.New(ctx, log, db))
+	router.Get("/albums/{id}", albumGet.New(ctx, log, db))
+	router.Post("/albums/{id}/tracks", attach.New(ctx, log, store))
+	router.Put("/albums/{id}/tracks", reorder.New(ctx, log, db))
 
 	// start server
 	srv := &http.Server{
@@ -107,7 +190,55 @@ func main() {
 	go serverUp(srv, log, signalChan)
 
 	// graceful shutdown
-	waitForGracefulShutdown(ctx, srv, db, log, signalChan)
+	waitForGracefulShutdown(ctx, srv, db, jobsPool, log, signalChan)
+}
+
+// newCache returns the cache of the configured mode. Unreachable Redis does not prevent the start,
+// requests fall back to the database until it is back.
+func newCache(ctx context.Context, log *slog.Logger, cfg config.Cache) (cache.Cache, error) {
+	const op = "cmd/app.newCache"
+
+	local := func() cache.Cache {
+		return lru.New(lru.Options{
+			Size:         cfg.LocalSize,
+			TTL:          cfg.LocalTTL,
+			TombstoneTTL: cfg.TombstoneTTL,
+		})
+	}
+	shared := func() cache.Cache {
+		rdCache := rd.New(rd.Options{
+			Address:      cfg.Address,
+			Username:     cfg.Username,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			PoolTimeout:  cfg.PoolTimeout,
+			TLS:          cfg.TLS,
+			TTL:          cfg.TTL,
+			TombstoneTTL: cfg.TombstoneTTL,
+		})
+		if err := rdCache.Ping(ctx); err != nil {
+			log.Warn("cache is unreachable, songs are read from database until it is back",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+		}
+		return rdCache
+	}
+
+	switch cfg.Mode {
+	case config.CacheRedis:
+		return shared(), nil
+	case config.CacheLocal:
+		return local(), nil
+	case config.CacheTiered:
+		return tiered.New(local(), shared()), nil
+	case config.CacheNone:
+		return noop.New(), nil
+	default:
+		return nil, fmt.Errorf("%s: unknown cache mode %q", op, cfg.Mode)
+	}
 }
 
 func serverUp(srv *http.Server, log *slog.Logger, signalChan chan os.Signal) {
@@ -122,8 +253,8 @@ func serverUp(srv *http.Server, log *slog.Logger, signalChan chan os.Signal) {
 	}
 }
 
-func waitForGracefulShutdown(ctx context.Context, srv *http.Server, db *postgres.Storage, log *slog.Logger,
-	signalChan chan os.Signal) {
+func waitForGracefulShutdown(ctx context.Context, srv *http.Server, db *postgres.Storage, jobsPool *jobs.Pool,
+	log *slog.Logger, signalChan chan os.Signal) {
 
 	const op = "cmd/app.gracefulShutdown"
 
@@ -132,6 +263,9 @@ func waitForGracefulShutdown(ctx context.Context, srv *http.Server, db *postgres
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	jobsPool.Stop()
+	log.Info("job workers were stopped", slog.String("op", op))
+
 	db.GetPoolForGracefulShutdown().Close()
 	log.Info("all connections in database-pool were closed", slog.String("op", op))
 	err := srv.Shutdown(ctx)
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"rest/internal/clients/info"
+	"rest/internal/config"
+	"rest/internal/importer"
+	"rest/internal/storage/postgres"
+	"syscall"
+)
+
+// importer imports songs from CSV or NDJSON file into the database configured by CONFIG_PATH
+// and prints the JSON report with the result of every row to stdout.
+func main() {
+	var filePath, formatName, externalAPI string
+	var concurrency, batchSize int
+
+	flag.StringVar(&filePath, "file", "", "Path to the CSV or NDJSON file, stdin is read when omitted")
+	flag.StringVar(&formatName, "format", "", "File format: csv or ndjson, taken from the file extension when omitted")
+	flag.StringVar(&externalAPI, "external-api", "", "Base URL of the external info API, EXTERNAL_API_URL when omitted")
+	flag.IntVar(&concurrency, "concurrency", importer.DefaultConcurrency, "Maximum number of simultaneous external API requests")
+	flag.IntVar(&batchSize, "batch-size", importer.DefaultBatchSize, "Number of rows inserted at once")
+
+	flag.Parse()
+
+	if formatName == "" {
+		formatName = filepath.Ext(filePath)
+		if len(formatName) > 0 {
+			formatName = formatName[1:]
+		}
+	}
+	format, err := importer.ParseFormat(formatName)
+	if err != nil {
+		fail(err)
+	}
+
+	var file io.Reader = os.Stdin
+	if filePath != "" {
+		f, err := os.Open(filePath)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		file = f
+	}
+
+	cfg := config.MustLoad()
+
+	// stdout is taken by the report
+	log := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := postgres.New(ctx,
+		fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
+			cfg.Database.User,
+			cfg.Database.Password,
+			cfg.Database.Host,
+			cfg.Database.Name),
+	)
+	if err != nil {
+		fail(err)
+	}
+	defer db.GetPoolForGracefulShutdown().Close()
+
+	if externalAPI == "" {
+		externalAPI = cfg.ExternalAPI.URL
+	}
+	infoClient, err := info.New(externalAPI, info.Options{
+		Timeout:          cfg.ExternalAPI.Timeout,
+		Retries:          cfg.ExternalAPI.Retries,
+		RetryMinDelay:    cfg.ExternalAPI.RetryMinDelay,
+		RetryMaxDelay:    cfg.ExternalAPI.RetryMaxDelay,
+		BreakerThreshold: cfg.ExternalAPI.BreakerThreshold,
+		BreakerCooldown:  cfg.ExternalAPI.BreakerCooldown,
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	songsImporter := importer.New(log, db, infoClient, importer.Options{
+		Concurrency: concurrency,
+		BatchSize:   batchSize,
+	})
+
+	report, importErr := songsImporter.Import(ctx, file, format)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		fail(err)
+	}
+	if importErr != nil {
+		fail(importErr)
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d of %d songs, %d failed\n", report.Imported, report.Total, report.Failed)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
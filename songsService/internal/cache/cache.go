@@ -2,36 +2,74 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"rest/internal/storage"
 	"rest/pkg/models"
-	"time"
 )
 
-type IDatabase interface {
-	GetAllSongs(ctx context.Context) ([]models.SongDTO, error)
-}
+// ErrMiss is returned by caches when there is no entry for the requested key.
+var ErrMiss = errors.New("cache miss")
 
-type ISongsLoader interface {
+// Cache keeps songs by id. Get and GetVerses return ErrMiss for songs which are not cached.
+// Set and Update never replace the cached song with an older version of it and do nothing
+// for songs which were just removed with Del, so readers racing with writers can not cache stale songs.
+type Cache interface {
+	Get(ctx context.Context, id int64) (*models.SongDTO, error)
+	GetVerses(ctx context.Context, id int64, verse, limit int) (string, error)
 	Set(ctx context.Context, song *models.SongDTO) error
+	// SetMany caches the songs as Set does, caches which can write them at once do so.
+	SetMany(ctx context.Context, songs []*models.SongDTO) error
+	Update(ctx context.Context, song *models.SongDTO) error
+	Del(ctx context.Context, ids ...int64) error
+	// TopSongs returns ids of at most n songs requested most often, caches which do not count requests return none.
+	TopSongs(ctx context.Context, n int) ([]int64, error)
+	// Hit counts requests of the songs served by a cache in front of this one,
+	// caches which do not count requests do nothing.
+	Hit(ctx context.Context, ids ...int64) error
+}
+
+type SongGetter interface {
+	GetSong(ctx context.Context, id int64) (*models.SongDTO, error)
 }
 
-func Load(ctx context.Context, cache ISongsLoader, db IDatabase) error {
-	const op = "cache.CacheLoad"
+type SongsWarmer interface {
+	TopSongs(ctx context.Context, n int) ([]int64, error)
+	SetMany(ctx context.Context, songs []*models.SongDTO) error
+}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+// Warmup puts n most requested songs to cache. Songs are otherwise cached on first read,
+// so warmup only saves the first misses after restart and is meant to run in background.
+func Warmup(ctx context.Context, log *slog.Logger, cache SongsWarmer, db SongGetter, n int) error {
+	const op = "cache.Warmup"
 
-	songs, err := db.GetAllSongs(ctx)
+	ids, err := cache.TopSongs(ctx, n)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	for _, song := range songs {
-		if err = cache.Set(ctx, &song); err != nil {
+	songs := make([]*models.SongDTO, 0, len(ids))
+	for _, id := range ids {
+		if err = ctx.Err(); err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
+
+		song, err := db.GetSong(ctx, id)
+		if errors.Is(err, storage.ErrSongNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		songs = append(songs, song)
 	}
 
-	return nil
+	if err = cache.SetMany(ctx, songs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("cache was warmed up", slog.String("op", op), slog.Int("songs", len(songs)))
 
+	return nil
 }
@@ -0,0 +1,171 @@
+// Package lru is an in-process cache of songs which evicts the least recently used songs above the size limit.
+package lru
+
+import (
+	"container/list"
+	"context"
+	"rest/internal/cache"
+	"rest/pkg/models"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSize         = 10000
+	DefaultTombstoneTTL = 10 * time.Second
+)
+
+// Options of the cache, zero Size and TombstoneTTL are replaced with defaults.
+// Songs expire after TTL, 0 keeps them until they are evicted or invalidated.
+type Options struct {
+	Size         int
+	TTL          time.Duration
+	TombstoneTTL time.Duration
+}
+
+// entry is a cached song or a tombstone of the removed song when song is nil.
+type entry struct {
+	id      int64
+	song    *models.SongDTO
+	expires time.Time
+}
+
+type Cache struct {
+	size         int
+	ttl          time.Duration
+	tombstoneTTL time.Duration
+
+	mu    sync.Mutex
+	items map[int64]*list.Element
+	// order holds entries from the most to the least recently used
+	order *list.List
+}
+
+func New(opts Options) *Cache {
+	if opts.Size <= 0 {
+		opts.Size = DefaultSize
+	}
+	if opts.TombstoneTTL <= 0 {
+		opts.TombstoneTTL = DefaultTombstoneTTL
+	}
+
+	return &Cache{
+		size:         opts.Size,
+		ttl:          opts.TTL,
+		tombstoneTTL: opts.TombstoneTTL,
+		items:        make(map[int64]*list.Element),
+		order:        list.New(),
+	}
+}
+
+// Get returns the cached song or cache.ErrMiss, entries without version are not served like in other caches.
+func (c *Cache) Get(ctx context.Context, id int64) (*models.SongDTO, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(id)
+	if e == nil || e.song == nil || e.song.Version == 0 || e.song.UpdatedAt.IsZero() {
+		return nil, cache.ErrMiss
+	}
+
+	song := *e.song
+	return &song, nil
+}
+
+func (c *Cache) GetVerses(ctx context.Context, id int64, verse, limit int) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(id)
+	if e == nil || e.song == nil || e.song.Text == "" {
+		return "", cache.ErrMiss
+	}
+
+	return models.Verses(e.song.Text, verse, limit), nil
+}
+
+func (c *Cache) Set(ctx context.Context, song *models.SongDTO) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e := c.lookup(song.Id); e != nil && (e.song == nil || e.song.Version >= song.Version) {
+		return nil
+	}
+
+	copied := *song
+	c.put(song.Id, &copied, c.ttl)
+	return nil
+}
+
+func (c *Cache) SetMany(ctx context.Context, songs []*models.SongDTO) error {
+	for _, song := range songs {
+		if err := c.Set(ctx, song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) Update(ctx context.Context, song *models.SongDTO) error {
+	return c.Set(ctx, song)
+}
+
+// Del replaces the songs with tombstones, so they can not be cached again for a while.
+func (c *Cache) Del(ctx context.Context, ids ...int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		c.put(id, nil, c.tombstoneTTL)
+	}
+	return nil
+}
+
+// TopSongs returns nothing, requests are not counted since the cache is empty after restart anyway.
+func (c *Cache) TopSongs(ctx context.Context, n int) ([]int64, error) {
+	return nil, nil
+}
+
+// Hit does nothing, see TopSongs.
+func (c *Cache) Hit(ctx context.Context, ids ...int64) error {
+	return nil
+}
+
+// lookup returns the live entry and marks it as recently used, expired entries are removed.
+func (c *Cache) lookup(id int64) *entry {
+	element, ok := c.items[id]
+	if !ok {
+		return nil
+	}
+
+	e := element.Value.(*entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.order.Remove(element)
+		delete(c.items, id)
+		return nil
+	}
+
+	c.order.MoveToFront(element)
+	return e
+}
+
+// put stores the entry as the most recently used one and evicts the least recently used entries above the size.
+func (c *Cache) put(id int64, song *models.SongDTO, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.items[id]; ok {
+		element.Value = &entry{id: id, song: song, expires: expires}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[id] = c.order.PushFront(&entry{id: id, song: song, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).id)
+	}
+}
@@ -0,0 +1,104 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"rest/internal/cache"
+	"rest/pkg/models"
+	"testing"
+	"time"
+)
+
+func testSong(id int64, version int64, text string) *models.SongDTO {
+	return &models.SongDTO{Id: id, GroupName: "Muse", SongName: "Uprising", Text: text, Version: version,
+		UpdatedAt: time.Now()}
+}
+
+func TestSetAfterDelIsRefused(t *testing.T) {
+	ctx := context.Background()
+	c := New(Options{Size: 10, TombstoneTTL: 20 * time.Millisecond})
+
+	stale := testSong(42, 1, "stale")
+	_ = c.Set(ctx, stale)
+	_ = c.Del(ctx, 42)
+
+	_ = c.Set(ctx, stale)
+	_ = c.SetMany(ctx, []*models.SongDTO{stale})
+	_ = c.Update(ctx, testSong(42, 2, "updated"))
+	if _, err := c.Get(ctx, 42); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get after Del = %v, want ErrMiss", err)
+	}
+	if _, err := c.GetVerses(ctx, 42, 1, 1); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("GetVerses after Del = %v, want ErrMiss", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	_ = c.Set(ctx, testSong(42, 3, "fresh"))
+	if got, err := c.Get(ctx, 42); err != nil || got.Version != 3 {
+		t.Errorf("Get after tombstone expired = %v, %v, want version 3", got, err)
+	}
+}
+
+func TestOlderVersionIsRefused(t *testing.T) {
+	ctx := context.Background()
+	c := New(Options{Size: 10})
+
+	_ = c.Update(ctx, testSong(42, 5, "newer"))
+	_ = c.Set(ctx, testSong(42, 4, "older"))
+	_ = c.Update(ctx, testSong(42, 4, "older"))
+	_ = c.SetMany(ctx, []*models.SongDTO{testSong(42, 4, "older")})
+	_ = c.Set(ctx, testSong(42, 5, "same version"))
+
+	got, err := c.Get(ctx, 42)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Version != 5 || got.Text != "newer" {
+		t.Errorf("Get = version %d text %q, want version 5 text newer", got.Version, got.Text)
+	}
+}
+
+func TestCachedSongIsCopied(t *testing.T) {
+	ctx := context.Background()
+	c := New(Options{Size: 10})
+
+	song := testSong(1, 1, "text")
+	_ = c.Set(ctx, song)
+	song.Text = "changed by caller"
+
+	got, _ := c.Get(ctx, 1)
+	if got.Text != "text" {
+		t.Errorf("cached text = %q, want text", got.Text)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	ctx := context.Background()
+	c := New(Options{Size: 2})
+
+	_ = c.Set(ctx, testSong(1, 1, ""))
+	_ = c.Set(ctx, testSong(2, 1, ""))
+	_, _ = c.Get(ctx, 1)
+	_ = c.Set(ctx, testSong(3, 1, ""))
+
+	if _, err := c.Get(ctx, 2); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("least recently used song was not evicted, Get = %v", err)
+	}
+	for _, id := range []int64{1, 3} {
+		if _, err := c.Get(ctx, id); err != nil {
+			t.Errorf("Get(%d) = %v", id, err)
+		}
+	}
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	c := New(Options{Size: 10, TTL: 20 * time.Millisecond})
+
+	_ = c.Set(ctx, testSong(1, 1, ""))
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := c.Get(ctx, 1); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get of expired song = %v, want ErrMiss", err)
+	}
+}
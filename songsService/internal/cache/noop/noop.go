@@ -0,0 +1,46 @@
+// Package noop is a cache which keeps nothing, every read is a miss and every request goes to the database.
+package noop
+
+import (
+	"context"
+	"rest/internal/cache"
+	"rest/pkg/models"
+)
+
+type Cache struct{}
+
+func New() *Cache {
+	return &Cache{}
+}
+
+func (c *Cache) Get(ctx context.Context, id int64) (*models.SongDTO, error) {
+	return nil, cache.ErrMiss
+}
+
+func (c *Cache) GetVerses(ctx context.Context, id int64, verse, limit int) (string, error) {
+	return "", cache.ErrMiss
+}
+
+func (c *Cache) Set(ctx context.Context, song *models.SongDTO) error {
+	return nil
+}
+
+func (c *Cache) SetMany(ctx context.Context, songs []*models.SongDTO) error {
+	return nil
+}
+
+func (c *Cache) Update(ctx context.Context, song *models.SongDTO) error {
+	return nil
+}
+
+func (c *Cache) Del(ctx context.Context, ids ...int64) error {
+	return nil
+}
+
+func (c *Cache) TopSongs(ctx context.Context, n int) ([]int64, error) {
+	return nil, nil
+}
+
+func (c *Cache) Hit(ctx context.Context, ids ...int64) error {
+	return nil
+}
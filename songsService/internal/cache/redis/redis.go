@@ -2,80 +2,295 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	r "github.com/go-redis/redis"
+	r "github.com/redis/go-redis/v9"
+	"rest/internal/cache"
+	tp "rest/internal/lib/timeParser"
 	"rest/pkg/models"
 	"strconv"
-	"strings"
 	"time"
 )
 
+// keyPrefix namespaces keys of songs in the keyspace shared with other data.
+const keyPrefix = "songs:"
+
+// DefaultTombstoneTTL is how long a removed song can not be cached again. Readers which got the song
+// from the database just before it was changed finish well within it.
+const DefaultTombstoneTTL = 10 * time.Second
+
+// hitsKey is the sorted set counting cache hits of songs by id, the most requested songs are warmed up on start.
+const hitsKey = keyPrefix + "hits"
+
+// DefaultHitsLimit is the number of the most requested songs whose hits are kept.
+const DefaultHitsLimit = 10000
+
+// Options of the cache. Entries expire after TTL, 0 keeps them until they are invalidated.
+// Removed songs can not be cached again for TombstoneTTL, DefaultTombstoneTTL is used when it is 0.
+// Zero PoolSize, MinIdleConns and PoolTimeout leave the defaults of the client, TLS enables TLS connections.
+// Hits are counted for at most HitsLimit songs, DefaultHitsLimit is used when it is 0.
+type Options struct {
+	Address      string
+	Username     string
+	Password     string
+	DB           int
+	PoolSize     int
+	MinIdleConns int
+	PoolTimeout  time.Duration
+	TLS          bool
+	TTL          time.Duration
+	TombstoneTTL time.Duration
+	HitsLimit    int
+}
+
 type Cache struct {
-	redisClient *r.Client
+	redisClient  *r.Client
+	ttl          time.Duration
+	tombstoneTTL time.Duration
+	hitsLimit    int
 }
 
-func New(ctx context.Context, addr string) (*Cache, error) {
-	const op = "cache/redis.New"
+// New returns the cache without connecting to Redis, connections are made on demand,
+// so the cache starts working as soon as Redis is reachable.
+func New(opts Options) *Cache {
+	redisOpts := &r.Options{
+		Addr:         opts.Address,
+		Username:     opts.Username,
+		Password:     opts.Password,
+		DB:           opts.DB,
+		PoolSize:     opts.PoolSize,
+		MinIdleConns: opts.MinIdleConns,
+		PoolTimeout:  opts.PoolTimeout,
+	}
+	if opts.TLS {
+		redisOpts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	rcl := r.NewClient(redisOpts)
+
+	if opts.TombstoneTTL <= 0 {
+		opts.TombstoneTTL = DefaultTombstoneTTL
+	}
+	if opts.HitsLimit <= 0 {
+		opts.HitsLimit = DefaultHitsLimit
+	}
+
+	return &Cache{redisClient: rcl, ttl: opts.TTL, tombstoneTTL: opts.TombstoneTTL, hitsLimit: opts.HitsLimit}
+}
+
+// Ping checks that Redis is reachable.
+func (c *Cache) Ping(ctx context.Context) error {
+	const op = "cache/redis.Ping"
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rcl := r.NewClient(&r.Options{
-		Addr: addr,
-	})
-	err := rcl.Ping().Err()
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	if err := c.redisClient.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Cache{redisClient: rcl}, nil
+	return nil
 }
 
+// Set caches the song unless the cached entry is of the same or newer version or the song was just deleted,
+// so a reader which got the song before it was changed or deleted can not overwrite the cache with it.
 func (c *Cache) Set(ctx context.Context, song *models.SongDTO) error {
 	const op = "cache/redis.Set"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	m := map[string]interface{}{
-		"group_name":   song.GroupName,
-		"song_name":    song.SongName,
-		"release_date": song.ReleaseDate,
-		"link":         song.Link,
-		"song_text":    song.Text,
-	}
-
-	err := c.redisClient.HMSet(strconv.FormatInt(song.Id, 10), m).Err()
-	if err != nil {
+	if err := c.set(ctx, song); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
 
+// Update replaces the cached entry with the song as it is after the update.
 func (c *Cache) Update(ctx context.Context, song *models.SongDTO) error {
 	const op = "cache/redis.Update"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	m := make(map[string]interface{}, 5)
-	if song.GroupName != "" {
-		m["group_name"] = song.GroupName
+	if err := c.set(ctx, song); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// setScript writes the hash KEYS[1] unless the tombstone KEYS[2] exists or the hash has version
+// not less than ARGV[1], the hash expires in ARGV[2] milliseconds unless it is 0,
+// the rest of ARGV are fields and values of the hash.
+var setScript = r.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+    return 0
+end
+local cached = tonumber(redis.call('HGET', KEYS[1], 'version'))
+if cached and cached >= (tonumber(ARGV[1]) or 0) then
+    return 0
+end
+redis.call('HMSET', KEYS[1], unpack(ARGV, 3))
+if tonumber(ARGV[2]) > 0 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// SetMany caches the songs the same way as Set in a single round trip.
+func (c *Cache) SetMany(ctx context.Context, songs []*models.SongDTO) error {
+	const op = "cache/redis.SetMany"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if len(songs) == 0 {
+		return nil
+	}
+
+	cmds, err := c.redisClient.Pipelined(ctx, func(pipe r.Pipeliner) error {
+		for _, song := range songs {
+			keys, args := setArgs(song, c.ttl)
+			// queued commands can not fall back from EVALSHA, the script is sent with every song
+			setScript.Eval(ctx, pipe, keys, args...)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, r.Nil) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, cmd := range cmds {
+		if err = cmd.Err(); err != nil && !errors.Is(err, r.Nil) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) set(ctx context.Context, song *models.SongDTO) error {
+	keys, args := setArgs(song, c.ttl)
+
+	err := setScript.Run(ctx, c.redisClient, keys, args...).Err()
+	if err != nil && !errors.Is(err, r.Nil) {
+		return err
+	}
+
+	return nil
+}
+
+// setArgs returns keys and arguments of setScript caching the song.
+func setArgs(song *models.SongDTO, ttl time.Duration) ([]string, []interface{}) {
+	fields := songFields(song)
+	args := make([]interface{}, 0, 2*len(fields)+2)
+	args = append(args, fields["version"], ttl.Milliseconds())
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+
+	return []string{songKey(song.Id), tombstoneKey(song.Id)}, args
+}
+
+// countHit is the part of the read scripts adding the hit of the song ARGV[1] to the sorted set KEYS[2].
+// Once the set grows to twice ARGV[2] songs, it is trimmed to ARGV[2] most requested ones,
+// so songs new to the set have time to collect hits before they compete with the rest.
+const countHit = `
+    redis.call('ZINCRBY', KEYS[2], 1, ARGV[1])
+    if redis.call('ZCARD', KEYS[2]) >= 2 * tonumber(ARGV[2]) then
+        redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[2]) - 1)
+    end
+`
+
+// getScript returns fields and values of the hash KEYS[1], the hit is counted only for complete entries.
+var getScript = r.NewScript(`
+local version = redis.call('HGET', KEYS[1], 'version')
+local updated = redis.call('HGET', KEYS[1], 'updated_at')
+if version and version ~= '' and updated and updated ~= '' then` + countHit + `end
+return redis.call('HGETALL', KEYS[1])
+`)
+
+// versesScript returns the text of the song in the hash KEYS[1], the hit is counted only when there is the text.
+var versesScript = r.NewScript(`
+local text = redis.call('HGET', KEYS[1], 'song_text')
+if text and text ~= '' then` + countHit + `end
+return text
+`)
+
+// hitScript counts the hit of the song served without reading the hash KEYS[1].
+var hitScript = r.NewScript(countHit)
+
+// hitArgs returns keys and arguments of the read scripts.
+func (c *Cache) hitArgs(id int64) ([]string, []interface{}) {
+	return []string{songKey(id), hitsKey}, []interface{}{strconv.FormatInt(id, 10), c.hitsLimit}
+}
+
+// Get returns the cached song or cache.ErrMiss when there is no complete entry for the id.
+func (c *Cache) Get(ctx context.Context, id int64) (*models.SongDTO, error) {
+	const op = "cache/redis.Get"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	keys, args := c.hitArgs(id)
+	values, err := getScript.Run(ctx, c.redisClient, keys, args...).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	m := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		m[values[i]] = values[i+1]
+	}
+	if m["updated_at"] == "" || m["version"] == "" {
+		return nil, cache.ErrMiss
+	}
+
+	song := models.SongDTO{
+		Id:        id,
+		GroupName: m["group_name"],
+		SongName:  m["song_name"],
+		Text:      m["song_text"],
+		Link:      m["link"],
+	}
+	if song.UpdatedAt, err = time.Parse(time.RFC3339Nano, m["updated_at"]); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if song.SongName != "" {
-		m["song_name"] = song.SongName
+	if song.Version, err = strconv.ParseInt(m["version"], 10, 64); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if song.Link != "" {
-		m["link"] = song.Link
+	if m["release_date"] != "" {
+		if song.ReleaseDate, err = tp.ParseToDate(m["release_date"]); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 	}
-	if song.Text != "" {
-		m["song_text"] = song.Text
+
+	return &song, nil
+}
+
+// Del removes the songs from cache and keeps them from being cached again for a while.
+func (c *Cache) Del(ctx context.Context, ids ...int64) error {
+	const op = "cache/redis.Del"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
 	}
-	if !song.ReleaseDate.IsZero() {
-		m["release_date"] = song.ReleaseDate
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, songKey(id))
 	}
 
-	err := c.redisClient.HMSet(strconv.FormatInt(song.Id, 10), m).Err()
+	_, err := c.redisClient.TxPipelined(ctx, func(pipe r.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		for _, id := range ids {
+			pipe.Set(ctx, tombstoneKey(id), 1, c.tombstoneTTL)
+		}
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -83,42 +298,120 @@ func (c *Cache) Update(ctx context.Context, song *models.SongDTO) error {
 	return nil
 }
 
-func (c *Cache) Del(ctx context.Context, id int64) error {
-	const op = "cache/redis.Del"
+// TopSongs returns ids of at most n songs requested most often, only requests served from cache are counted.
+func (c *Cache) TopSongs(ctx context.Context, n int) ([]int64, error) {
+	const op = "cache/redis.TopSongs"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err := c.redisClient.HDel(strconv.FormatInt(id, 10)).Err()
+	members, err := c.redisClient.ZRevRange(ctx, hitsKey, 0, int64(n)-1).Result()
 	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Hit counts the hits of the songs served by a local cache, so they are taken into account by TopSongs.
+func (c *Cache) Hit(ctx context.Context, ids ...int64) error {
+	const op = "cache/redis.Hit"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cmds, err := c.redisClient.Pipelined(ctx, func(pipe r.Pipeliner) error {
+		for _, id := range ids {
+			keys, args := c.hitArgs(id)
+			// queued commands can not fall back from EVALSHA, the script is sent with every hit
+			hitScript.Eval(ctx, pipe, keys, args...)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, r.Nil) {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	for _, cmd := range cmds {
+		if err = cmd.Err(); err != nil && !errors.Is(err, r.Nil) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
 
 	return nil
 }
 
 func (c *Cache) GetVerses(ctx context.Context, id int64, verse, limit int) (string, error) {
-	const op = "cache/redis.GetVerse"
+	const op = "cache/redis.GetVerses"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	songText := c.redisClient.HGet(strconv.FormatInt(id, 10), "song_text").Val()
+	keys, args := c.hitArgs(id)
+	songText, err := versesScript.Run(ctx, c.redisClient, keys, args...).Text()
+	if err != nil && !errors.Is(err, r.Nil) {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 	if songText == "" {
-		return "", fmt.Errorf("%s: %w", op, fmt.Errorf("no song found with id %d", id))
+		return "", fmt.Errorf("%s: %w", op, cache.ErrMiss)
 	}
-	c.redisClient.Keys("song_")
 
-	verses := strings.Split(songText, "\n\n")
+	return models.Verses(songText, verse, limit), nil
+}
+
+// songKey returns the key of the hash holding the song.
+func songKey(id int64) string {
+	return keyPrefix + strconv.FormatInt(id, 10)
+}
+
+// tombstoneKey returns the key marking the song as just removed from cache.
+func tombstoneKey(id int64) string {
+	return keyPrefix + "deleted:" + strconv.FormatInt(id, 10)
+}
 
-	start := (verse - 1) * limit
-	end := start + limit
-	if start >= len(verses) {
-		start = 0
+func songFields(song *models.SongDTO) map[string]interface{} {
+	return map[string]interface{}{
+		"group_name":   song.GroupName,
+		"song_name":    song.SongName,
+		"release_date": formatDate(song.ReleaseDate),
+		"link":         song.Link,
+		"song_text":    song.Text,
+		"updated_at":   formatTimestamp(song.UpdatedAt),
+		"version":      formatVersion(song.Version),
 	}
-	if end > len(verses) {
-		end = 1
+}
+
+// formatDate keeps zero date empty, such songs inherit the release date from their album.
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
 	}
+	return tp.FormatDate(date)
+}
 
-	return strings.Join(verses[start:end], "\n\n"), nil
+// formatTimestamp keeps zero time empty, entries without updated_at or version are not served by Get.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatVersion(version int64) string {
+	if version == 0 {
+		return ""
+	}
+	return strconv.FormatInt(version, 10)
 }
@@ -0,0 +1,452 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"rest/internal/cache"
+	"rest/pkg/models"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T, opts Options) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	opts.Address = mr.Addr()
+	c := New(opts)
+	t.Cleanup(func() { _ = c.redisClient.Close() })
+
+	return c, mr
+}
+
+func testSong(id int64, version int64) *models.SongDTO {
+	return &models.SongDTO{
+		Id:          id,
+		GroupName:   "Muse",
+		SongName:    "Supermassive Black Hole",
+		ReleaseDate: time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC),
+		Text:        "first verse\n\nsecond verse\n\nthird verse",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+		UpdatedAt:   time.Date(2024, time.March, 1, 12, 30, 0, 123456789, time.UTC),
+		Version:     version,
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	song := testSong(42, 3)
+	if err := c.Set(ctx, song); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if !mr.Exists("songs:42") {
+		t.Fatalf("keys = %v, want songs:42", mr.Keys())
+	}
+	if got := mr.HGet("songs:42", "song_name"); got != song.SongName {
+		t.Errorf("song_name = %q, want %q", got, song.SongName)
+	}
+	if got := mr.HGet("songs:42", "version"); got != "3" {
+		t.Errorf("version = %q, want 3", got)
+	}
+
+	got, err := c.Get(ctx, 42)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !got.UpdatedAt.Equal(song.UpdatedAt) {
+		t.Errorf("updated_at = %v, want %v", got.UpdatedAt, song.UpdatedAt)
+	}
+	got.UpdatedAt = song.UpdatedAt
+	if *got != *song {
+		t.Errorf("Get = %+v, want %+v", got, song)
+	}
+}
+
+func TestGetMiss(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	if _, err := c.Get(ctx, 1); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get of missing song = %v, want ErrMiss", err)
+	}
+
+	// entries without version are not complete, they are left by old releases
+	mr.HSet("songs:2", "song_name", "Uprising", "group_name", "Muse")
+	if _, err := c.Get(ctx, 2); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get of incomplete entry = %v, want ErrMiss", err)
+	}
+}
+
+func TestSetKeepsZeroDateEmpty(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	song := testSong(5, 1)
+	song.ReleaseDate = time.Time{}
+	if err := c.Set(ctx, song); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if got := mr.HGet("songs:5", "release_date"); got != "" {
+		t.Errorf("release_date = %q, want empty", got)
+	}
+	got, err := c.Get(ctx, 5)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !got.ReleaseDate.IsZero() {
+		t.Errorf("release date = %v, want zero", got.ReleaseDate)
+	}
+}
+
+func TestAddedSongIsCachedUnderItsId(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	// songs are cached once the database has assigned their ids
+	for _, id := range []int64{7, 8} {
+		if err := c.Set(ctx, testSong(id, 1)); err != nil {
+			t.Fatalf("Set(%d): %v", id, err)
+		}
+	}
+
+	keys := mr.Keys()
+	if !slices.Equal(keys, []string{"songs:7", "songs:8"}) {
+		t.Errorf("keys = %v, want [songs:7 songs:8]", keys)
+	}
+	if mr.Exists("songs:0") {
+		t.Error("song was cached under id 0")
+	}
+	for _, id := range []int64{7, 8} {
+		got, err := c.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get(%d): %v", id, err)
+		}
+		if got.Id != id {
+			t.Errorf("Get(%d) returned song %d", id, got.Id)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	if err := c.Set(ctx, testSong(42, 1)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	updated := testSong(42, 2)
+	updated.Text = "new verse"
+	if err := c.Update(ctx, updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if got := mr.HGet("songs:42", "song_text"); got != "new verse" {
+		t.Errorf("song_text = %q, want new verse", got)
+	}
+	got, err := c.Get(ctx, 42)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Version != 2 || got.Text != "new verse" {
+		t.Errorf("Get = version %d text %q, want version 2 text %q", got.Version, got.Text, "new verse")
+	}
+}
+
+func TestDel(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{TombstoneTTL: time.Minute})
+
+	for _, id := range []int64{1, 2, 3} {
+		if err := c.Set(ctx, testSong(id, 1)); err != nil {
+			t.Fatalf("Set(%d): %v", id, err)
+		}
+	}
+
+	if err := c.Del(ctx, 1, 2); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	for _, id := range []int64{1, 2} {
+		key := "songs:" + strconv.FormatInt(id, 10)
+		if mr.Exists(key) {
+			t.Errorf("%s was not deleted", key)
+		}
+		tombstone := "songs:deleted:" + strconv.FormatInt(id, 10)
+		if !mr.Exists(tombstone) {
+			t.Errorf("%s was not set", tombstone)
+		}
+		if ttl := mr.TTL(tombstone); ttl != time.Minute {
+			t.Errorf("TTL of %s = %v, want 1m", tombstone, ttl)
+		}
+		if _, err := c.Get(ctx, id); !errors.Is(err, cache.ErrMiss) {
+			t.Errorf("Get(%d) after Del = %v, want ErrMiss", id, err)
+		}
+	}
+	if !mr.Exists("songs:3") {
+		t.Error("songs:3 was deleted")
+	}
+
+	if err := c.Del(ctx); err != nil {
+		t.Errorf("Del without ids: %v", err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{TTL: time.Hour})
+
+	if err := c.Set(ctx, testSong(1, 1)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if ttl := mr.TTL("songs:1"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want 1h", ttl)
+	}
+
+	mr.FastForward(time.Hour)
+	if _, err := c.Get(ctx, 1); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get of expired song = %v, want ErrMiss", err)
+	}
+}
+
+func TestGetVerses(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	if err := c.Set(ctx, testSong(42, 1)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	tests := []struct {
+		verse, limit int
+		want         string
+	}{
+		{1, 1, "first verse"},
+		{2, 1, "second verse"},
+		{1, 2, "first verse\n\nsecond verse"},
+		{1, 3, "first verse\n\nsecond verse\n\nthird verse"},
+		{2, 2, "third verse"},
+		{4, 1, ""},
+	}
+	for _, tt := range tests {
+		got, err := c.GetVerses(ctx, 42, tt.verse, tt.limit)
+		if err != nil {
+			t.Fatalf("GetVerses(%d, %d): %v", tt.verse, tt.limit, err)
+		}
+		if got != tt.want {
+			t.Errorf("GetVerses(%d, %d) = %q, want %q", tt.verse, tt.limit, got, tt.want)
+		}
+	}
+
+	if _, err := c.GetVerses(ctx, 43, 1, 1); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("GetVerses of missing song = %v, want ErrMiss", err)
+	}
+
+	for _, key := range mr.Keys() {
+		if key != "songs:42" && key != hitsKey {
+			t.Errorf("unexpected key %s", key)
+		}
+	}
+}
+
+func TestSetAfterDelIsRefused(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{TombstoneTTL: 10 * time.Second})
+
+	// the reader has got the song from the database just before it was deleted
+	stale := testSong(42, 1)
+	if err := c.Set(ctx, stale); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Del(ctx, 42); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	if err := c.Set(ctx, stale); err != nil {
+		t.Fatalf("Set after Del: %v", err)
+	}
+	if err := c.SetMany(ctx, []*models.SongDTO{stale}); err != nil {
+		t.Fatalf("SetMany after Del: %v", err)
+	}
+	if err := c.Update(ctx, testSong(42, 2)); err != nil {
+		t.Fatalf("Update after Del: %v", err)
+	}
+	if mr.Exists("songs:42") {
+		t.Error("song was cached again while its tombstone exists")
+	}
+	if _, err := c.Get(ctx, 42); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get after Del = %v, want ErrMiss", err)
+	}
+
+	mr.FastForward(10 * time.Second)
+	if err := c.Set(ctx, testSong(42, 3)); err != nil {
+		t.Fatalf("Set after tombstone expired: %v", err)
+	}
+	if got, err := c.Get(ctx, 42); err != nil || got.Version != 3 {
+		t.Errorf("Get after tombstone expired = %v, %v, want version 3", got, err)
+	}
+}
+
+func TestOlderVersionIsRefused(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	newer := testSong(42, 5)
+	newer.Text = "newer"
+	if err := c.Update(ctx, newer); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	older := testSong(42, 4)
+	older.Text = "older"
+	same := testSong(42, 5)
+	same.Text = "same version"
+	for name, write := range map[string]func() error{
+		"Set older":     func() error { return c.Set(ctx, older) },
+		"Update older":  func() error { return c.Update(ctx, older) },
+		"SetMany older": func() error { return c.SetMany(ctx, []*models.SongDTO{older}) },
+		"Set same":      func() error { return c.Set(ctx, same) },
+	} {
+		if err := write(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := mr.HGet("songs:42", "song_text"); got != "newer" {
+			t.Errorf("%s replaced the newer version, song_text = %q", name, got)
+		}
+	}
+
+	if got, err := c.Get(ctx, 42); err != nil || got.Version != 5 {
+		t.Errorf("Get = %v, %v, want version 5", got, err)
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{TTL: time.Hour})
+
+	if err := c.Update(ctx, testSong(2, 9)); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := c.SetMany(ctx, []*models.SongDTO{testSong(1, 1), testSong(2, 1), testSong(3, 1)}); err != nil {
+		t.Fatalf("SetMany: %v", err)
+	}
+
+	for id, version := range map[int64]int64{1: 1, 2: 9, 3: 1} {
+		got, err := c.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get(%d): %v", id, err)
+		}
+		if got.Version != version {
+			t.Errorf("Get(%d) version = %d, want %d", id, got.Version, version)
+		}
+	}
+	if ttl := mr.TTL("songs:3"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want 1h", ttl)
+	}
+
+	if err := c.SetMany(ctx, nil); err != nil {
+		t.Errorf("SetMany without songs: %v", err)
+	}
+}
+
+func TestHitsAreCountedOnlyForCachedSongs(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	for id := int64(1000); id < 1100; id++ {
+		_, _ = c.Get(ctx, id)
+		_, _ = c.GetVerses(ctx, id, 1, 1)
+	}
+	if mr.Exists(hitsKey) {
+		t.Fatalf("misses were counted: %v", mr.Keys())
+	}
+
+	if err := c.Set(ctx, testSong(1, 1)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set(ctx, testSong(2, 1)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	for range 3 {
+		_, _ = c.Get(ctx, 2)
+	}
+	_, _ = c.GetVerses(ctx, 2, 1, 1)
+	_, _ = c.Get(ctx, 1)
+
+	if score, _ := mr.ZScore(hitsKey, "2"); score != 4 {
+		t.Errorf("hits of song 2 = %v, want 4", score)
+	}
+	if score, _ := mr.ZScore(hitsKey, "1"); score != 1 {
+		t.Errorf("hits of song 1 = %v, want 1", score)
+	}
+
+	top, err := c.TopSongs(ctx, 10)
+	if err != nil {
+		t.Fatalf("TopSongs: %v", err)
+	}
+	if !slices.Equal(top, []int64{2, 1}) {
+		t.Errorf("TopSongs = %v, want [2 1]", top)
+	}
+}
+
+func TestHitsAreTrimmed(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{HitsLimit: 3})
+
+	// songs 1 and 2 are the most requested ones
+	for id := int64(1); id <= 10; id++ {
+		if err := c.Set(ctx, testSong(id, 1)); err != nil {
+			t.Fatalf("Set(%d): %v", id, err)
+		}
+	}
+	for range 5 {
+		_, _ = c.Get(ctx, 1)
+		_, _ = c.Get(ctx, 2)
+	}
+	for id := int64(3); id <= 10; id++ {
+		_, _ = c.Get(ctx, id)
+
+		members, err := mr.ZMembers(hitsKey)
+		if err != nil {
+			t.Fatalf("ZMembers: %v", err)
+		}
+		if len(members) >= 2*3 {
+			t.Fatalf("hits of %d songs are kept, want less than %d", len(members), 2*3)
+		}
+	}
+
+	top, err := c.TopSongs(ctx, 2)
+	if err != nil {
+		t.Fatalf("TopSongs: %v", err)
+	}
+	if !slices.Equal(top, []int64{2, 1}) && !slices.Equal(top, []int64{1, 2}) {
+		t.Errorf("TopSongs = %v, want songs 1 and 2", top)
+	}
+}
+
+func TestHitIsCountedWithoutEntry(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t, Options{})
+
+	if err := c.Hit(ctx, 7, 8, 7); err != nil {
+		t.Fatalf("Hit: %v", err)
+	}
+
+	top, err := c.TopSongs(ctx, 10)
+	if err != nil {
+		t.Fatalf("TopSongs: %v", err)
+	}
+	if !slices.Equal(top, []int64{7, 8}) {
+		t.Errorf("TopSongs = %v, want [7 8]", top)
+	}
+	if mr.Exists("songs:7") {
+		t.Error("hit created the entry of the song")
+	}
+}
@@ -0,0 +1,123 @@
+// Package tiered puts a local cache in front of a shared one. Reads are served by the local cache when possible
+// and fill it from the shared one, writes go to both. Other instances do not invalidate the local cache,
+// so its entries should expire soon to bound staleness.
+// Hits of the local cache are counted by the shared one in background, so TopSongs sees songs of all instances.
+package tiered
+
+import (
+	"context"
+	"rest/internal/cache"
+	"rest/pkg/models"
+)
+
+// MaxPendingHits limits hits being counted at once, hits above the limit are dropped,
+// counting is best effort and must not pile up while the shared cache is slow or unreachable.
+const MaxPendingHits = 64
+
+type Cache struct {
+	local  cache.Cache
+	shared cache.Cache
+
+	// pending holds a slot for every hit being counted
+	pending chan struct{}
+}
+
+func New(local cache.Cache, shared cache.Cache) *Cache {
+	return &Cache{local: local, shared: shared, pending: make(chan struct{}, MaxPendingHits)}
+}
+
+func (c *Cache) Get(ctx context.Context, id int64) (*models.SongDTO, error) {
+	if song, err := c.local.Get(ctx, id); err == nil {
+		c.hit(ctx, id)
+		return song, nil
+	}
+
+	song, err := c.shared.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.local.Set(ctx, song); err != nil {
+		return nil, err
+	}
+	return song, nil
+}
+
+func (c *Cache) GetVerses(ctx context.Context, id int64, verse, limit int) (string, error) {
+	if verses, err := c.local.GetVerses(ctx, id, verse, limit); err == nil {
+		c.hit(ctx, id)
+		return verses, nil
+	}
+
+	// the whole song is read, so the local cache is filled as by Get
+	song, err := c.shared.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err = c.local.Set(ctx, song); err != nil {
+		return "", err
+	}
+	if song.Text == "" {
+		return "", cache.ErrMiss
+	}
+	return models.Verses(song.Text, verse, limit), nil
+}
+
+func (c *Cache) Set(ctx context.Context, song *models.SongDTO) error {
+	// the local cache keeps working while the shared one is unreachable
+	err := c.shared.Set(ctx, song)
+	if localErr := c.local.Set(ctx, song); localErr != nil {
+		return localErr
+	}
+	return err
+}
+
+func (c *Cache) SetMany(ctx context.Context, songs []*models.SongDTO) error {
+	// the local cache keeps working while the shared one is unreachable
+	err := c.shared.SetMany(ctx, songs)
+	if localErr := c.local.SetMany(ctx, songs); localErr != nil {
+		return localErr
+	}
+	return err
+}
+
+func (c *Cache) Update(ctx context.Context, song *models.SongDTO) error {
+	// the local cache keeps working while the shared one is unreachable
+	err := c.shared.Update(ctx, song)
+	if localErr := c.local.Update(ctx, song); localErr != nil {
+		return localErr
+	}
+	return err
+}
+
+// Del invalidates the local cache first, it can not fail, unlike the shared one.
+func (c *Cache) Del(ctx context.Context, ids ...int64) error {
+	if err := c.local.Del(ctx, ids...); err != nil {
+		return err
+	}
+	return c.shared.Del(ctx, ids...)
+}
+
+func (c *Cache) TopSongs(ctx context.Context, n int) ([]int64, error) {
+	return c.shared.TopSongs(ctx, n)
+}
+
+func (c *Cache) Hit(ctx context.Context, ids ...int64) error {
+	return c.shared.Hit(ctx, ids...)
+}
+
+// hit counts the local hit in the shared cache without waiting for it, the hit is dropped
+// when too many hits are being counted already.
+func (c *Cache) hit(ctx context.Context, id int64) {
+	select {
+	case c.pending <- struct{}{}:
+	default:
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() { <-c.pending }()
+		_ = c.shared.Hit(ctx, id)
+	}()
+}
@@ -0,0 +1,95 @@
+package tiered
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"rest/internal/cache/lru"
+	rd "rest/internal/cache/redis"
+	"rest/pkg/models"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T) (*Cache, *lru.Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	local := lru.New(lru.Options{})
+	return New(local, rd.New(rd.Options{Address: mr.Addr()})), local, mr
+}
+
+func testSong(id int64) *models.SongDTO {
+	return &models.SongDTO{
+		Id:        id,
+		GroupName: "Muse",
+		SongName:  "Starlight",
+		Text:      "first verse\n\nsecond verse",
+		UpdatedAt: time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC),
+		Version:   1,
+	}
+}
+
+// waitHits waits until the song has the number of hits in the shared cache.
+func waitHits(t *testing.T, mr *miniredis.Miniredis, id string, hits float64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, err := mr.ZScore("songs:hits", id)
+		if err == nil && got == hits {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("hits of song %s = %v (%v), want %v", id, got, err, hits)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLocalHitsAreCountedInSharedCache(t *testing.T) {
+	ctx := context.Background()
+	c, _, mr := newTestCache(t)
+
+	if err := c.Set(ctx, testSong(7)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := c.Get(ctx, 7); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, err := c.GetVerses(ctx, 7, 1, 1); err != nil {
+		t.Fatalf("GetVerses: %v", err)
+	}
+	waitHits(t, mr, "7", 2)
+
+	top, err := c.TopSongs(ctx, 10)
+	if err != nil {
+		t.Fatalf("TopSongs: %v", err)
+	}
+	if len(top) != 1 || top[0] != 7 {
+		t.Errorf("TopSongs = %v, want [7]", top)
+	}
+}
+
+func TestGetVersesFillsLocalCache(t *testing.T) {
+	ctx := context.Background()
+	c, local, mr := newTestCache(t)
+
+	// the song was cached by another instance
+	shared := rd.New(rd.Options{Address: mr.Addr()})
+	if err := shared.Set(ctx, testSong(7)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	verses, err := c.GetVerses(ctx, 7, 2, 1)
+	if err != nil {
+		t.Fatalf("GetVerses: %v", err)
+	}
+	if verses != "second verse" {
+		t.Errorf("GetVerses = %q, want second verse", verses)
+	}
+	if song, err := local.Get(ctx, 7); err != nil || song.Version != 1 {
+		t.Errorf("local Get = %+v, %v, want the song", song, err)
+	}
+	// the shared cache counted the read, as it does for Get
+	waitHits(t, mr, "7", 1)
+}
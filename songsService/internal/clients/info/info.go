@@ -0,0 +1,237 @@
+// Package info is a client of the external API providing details of songs.
+package info
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"rest/internal/lib/breaker"
+	"rest/pkg/models"
+	"time"
+)
+
+const (
+	DefaultTimeout          = 10 * time.Second
+	DefaultRetries          = 2
+	DefaultRetryMinDelay    = 200 * time.Millisecond
+	DefaultRetryMaxDelay    = 2 * time.Second
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// Kinds of client errors, every error returned by the client wraps exactly one kind.
+// ErrClient means the external API rejected the request, repeating it does not help,
+// other kinds are failures of the external API or of the network.
+var (
+	ErrClient      = errors.New("external API rejected the request")
+	ErrServer      = errors.New("external API failed")
+	ErrDecode      = errors.New("unable to decode external API response")
+	ErrUnavailable = errors.New("external API is unavailable")
+)
+
+// Error is an error of a certain kind, StatusCode is set when the external API responded.
+// Msg is the error reported by the external API or the description of the failure.
+type Error struct {
+	Kind       error
+	StatusCode int
+	Msg        string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := e.Kind.Error()
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" with status %d", e.StatusCode)
+	}
+	if e.Msg != "" {
+		msg += ": " + e.Msg
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// InfoGetter returns text, release date and link of the song.
+type InfoGetter interface {
+	GetInfo(ctx context.Context, groupName string, songName string) (*models.SongDTO, error)
+}
+
+// Options of the client, zero values are replaced with defaults.
+// Timeout limits every attempt, failed attempts are retried Retries times, negative Retries disables retries.
+// Delays between attempts grow from RetryMinDelay up to RetryMaxDelay and are jittered.
+// The breaker opens after BreakerThreshold failed attempts in a row and stays open for BreakerCooldown.
+type Options struct {
+	Timeout          time.Duration
+	Retries          int
+	RetryMinDelay    time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Client requests the /info endpoint of the external API.
+type Client struct {
+	baseURL *url.URL
+	opts    Options
+	client  *http.Client
+	breaker *breaker.Breaker
+}
+
+// New returns the client of the API at baseURL.
+func New(baseURL string, opts Options) (*Client, error) {
+	const op = "clients/info.New"
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%s: invalid base URL %q", op, baseURL)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retries == 0 {
+		opts.Retries = DefaultRetries
+	}
+	if opts.RetryMinDelay <= 0 {
+		opts.RetryMinDelay = DefaultRetryMinDelay
+	}
+	if opts.RetryMaxDelay < opts.RetryMinDelay {
+		opts.RetryMaxDelay = max(DefaultRetryMaxDelay, opts.RetryMinDelay)
+	}
+	if opts.BreakerThreshold <= 0 {
+		opts.BreakerThreshold = DefaultBreakerThreshold
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = DefaultBreakerCooldown
+	}
+
+	return &Client{
+		baseURL: u,
+		opts:    opts,
+		client:  &http.Client{},
+		breaker: breaker.New(opts.BreakerThreshold, opts.BreakerCooldown),
+	}, nil
+}
+
+type infoResponse struct {
+	Text        string    `json:"text"`
+	ReleaseDate time.Time `json:"release_date"`
+	Link        string    `json:"link"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// GetInfo returns details of the song, only Text, ReleaseDate and Link of the result are set.
+// Failures of the external API are retried, while the breaker is open the call fails at once with ErrUnavailable.
+func (c *Client) GetInfo(ctx context.Context, groupName string, songName string) (*models.SongDTO, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.Allow(); err != nil {
+			return nil, &Error{Kind: ErrUnavailable, Err: err}
+		}
+
+		song, err := c.getInfo(ctx, groupName, songName)
+		switch {
+		case err == nil || errors.Is(err, ErrClient):
+			// the external API is fine when it rejects the request
+			c.breaker.Success()
+		case ctx.Err() != nil:
+			c.breaker.Release()
+		default:
+			c.breaker.Failure()
+		}
+
+		if err == nil {
+			return song, nil
+		}
+		if attempt >= c.opts.Retries || !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+}
+
+// BreakerState returns the state of the circuit breaker guarding the external API.
+func (c *Client) BreakerState() breaker.Snapshot {
+	return c.breaker.Snapshot()
+}
+
+// retryable reports whether the failed request may succeed when repeated,
+// GET requests are idempotent, so failures of the external API and of the network are retried.
+func retryable(err error) bool {
+	return errors.Is(err, ErrServer) || errors.Is(err, ErrUnavailable)
+}
+
+// backoff returns the delay before the next attempt, the delay doubles with every attempt
+// and a random half of it is taken so clients failed at once do not retry at once.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.opts.RetryMinDelay
+	for i := 0; i < attempt && delay < c.opts.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, c.opts.RetryMaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (c *Client) getInfo(ctx context.Context, groupName string, songName string) (*models.SongDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	u := c.baseURL.JoinPath("info")
+	query := url.Values{}
+	query.Set("group_name", groupName)
+	query.Set("song_name", songName)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, &Error{Kind: ErrClient, Msg: "unable to build request", Err: err}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, &Error{Kind: ErrUnavailable, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		kind := ErrServer
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			kind = ErrClient
+		}
+		// error responses are not guaranteed to be JSON, the message is best effort
+		var info infoResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&info)
+		return nil, &Error{Kind: kind, StatusCode: resp.StatusCode, Msg: info.Error}
+	}
+
+	var info infoResponse
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		if ctx.Err() != nil {
+			return nil, &Error{Kind: ErrUnavailable, Err: err}
+		}
+		return nil, &Error{Kind: ErrDecode, Err: err}
+	}
+
+	return &models.SongDTO{Text: info.Text, ReleaseDate: info.ReleaseDate, Link: info.Link}, nil
+}
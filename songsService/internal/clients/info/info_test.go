@@ -0,0 +1,180 @@
+package info
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"rest/internal/lib/breaker"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns the client of the server responding with handler, failed requests are not retried.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts Options) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if opts.Retries == 0 {
+		opts.Retries = -1
+	}
+	client, err := New(server.URL, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client
+}
+
+func TestGetInfo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info" || r.URL.Query().Get("group_name") != "Muse" ||
+			r.URL.Query().Get("song_name") != "Supermassive Black Hole" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"Ooh baby","release_date":"2006-07-16T00:00:00Z","link":"https://example.com"}`))
+	}, Options{})
+
+	song, err := client.GetInfo(context.Background(), "Muse", "Supermassive Black Hole")
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+	if song.Text != "Ooh baby" || song.Link != "https://example.com" ||
+		!song.ReleaseDate.Equal(time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetInfo() = %+v", song)
+	}
+}
+
+func TestGetInfoErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		kind       error
+		statusCode int
+		msg        string
+	}{
+		{"bad request", http.StatusBadRequest, `{"error":"unknown song"}`, ErrClient, http.StatusBadRequest, "unknown song"},
+		{"bad request without JSON", http.StatusNotFound, `not found`, ErrClient, http.StatusNotFound, ""},
+		{"server error", http.StatusInternalServerError, `{"error":"oops"}`, ErrServer, http.StatusInternalServerError, "oops"},
+		{"malformed JSON", http.StatusOK, `{"text":`, ErrDecode, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}, Options{})
+
+			_, err := client.GetInfo(context.Background(), "Muse", "Hysteria")
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("GetInfo() error = %v, want %v", err, tt.kind)
+			}
+			var infoErr *Error
+			if !errors.As(err, &infoErr) {
+				t.Fatalf("GetInfo() error = %T, want *Error", err)
+			}
+			if infoErr.StatusCode != tt.statusCode || infoErr.Msg != tt.msg {
+				t.Errorf("GetInfo() status = %d, msg = %q, want %d, %q",
+					infoErr.StatusCode, infoErr.Msg, tt.statusCode, tt.msg)
+			}
+		})
+	}
+}
+
+func TestGetInfoTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, Options{Timeout: 50 * time.Millisecond})
+	defer close(release)
+
+	start := time.Now()
+	_, err := client.GetInfo(context.Background(), "Muse", "Hysteria")
+	if !errors.Is(err, ErrUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetInfo() error = %v, want %v and %v", err, ErrUnavailable, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetInfo() returned after %v, want about the timeout", elapsed)
+	}
+}
+
+// failingServer fails the first failures requests with status and then responds with the song,
+// the returned function reports the number of requests.
+func failingServer(t *testing.T, status int, failures int32, opts Options) (*Client, func() int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":"text"}`))
+	}, opts)
+	return client, requests.Load
+}
+
+func TestGetInfoRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		failures int32
+		retries  int
+		requests int32
+		err      error
+	}{
+		{"server error is retried", http.StatusInternalServerError, 2, 2, 3, nil},
+		{"retries are limited", http.StatusBadGateway, 5, 2, 3, ErrServer},
+		{"negative retries disable retries", http.StatusServiceUnavailable, 1, -1, 1, ErrServer},
+		{"bad request is not retried", http.StatusBadRequest, 1, 2, 1, ErrClient},
+		{"not found is not retried", http.StatusNotFound, 1, 2, 1, ErrClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := failingServer(t, tt.status, tt.failures, Options{
+				Retries:          tt.retries,
+				RetryMinDelay:    time.Millisecond,
+				RetryMaxDelay:    time.Millisecond,
+				BreakerThreshold: 100,
+			})
+
+			_, err := client.GetInfo(context.Background(), "Muse", "Hysteria")
+			if !errors.Is(err, tt.err) {
+				t.Errorf("GetInfo() error = %v, want %v", err, tt.err)
+			}
+			if got := requests(); got != tt.requests {
+				t.Errorf("requests = %d, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestGetInfoBreakerStopsRetries(t *testing.T) {
+	client, requests := failingServer(t, http.StatusInternalServerError, 100, Options{
+		Retries:          5,
+		RetryMinDelay:    time.Millisecond,
+		RetryMaxDelay:    time.Millisecond,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+
+	_, err := client.GetInfo(context.Background(), "Muse", "Hysteria")
+	if !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("GetInfo() error = %v, want %v", err, breaker.ErrOpen)
+	}
+	if got := requests(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if state := client.BreakerState().State; state != breaker.Open {
+		t.Errorf("breaker state = %s, want %s", state, breaker.Open)
+	}
+}
@@ -4,15 +4,18 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 )
 
 type Config struct {
-	Env        string `env:"ENV" env-default:"local"`
-	Database   Database
-	HTTPServer HTTPServer
-	Cache      Cache
+	Env         string `env:"ENV" env-default:"local"`
+	Database    Database
+	HTTPServer  HTTPServer
+	Cache       Cache
+	Jobs        Jobs
+	ExternalAPI ExternalAPI
 }
 
 type HTTPServer struct {
@@ -28,8 +31,105 @@ type Database struct {
 	Password string `env:"DB_PASSWORD" env-default:"postgres"`
 }
 
+// Modes of the cache: Redis shared by instances, local in-process LRU, local LRU in front of Redis or no cache.
+const (
+	CacheRedis  = "redis"
+	CacheLocal  = "local"
+	CacheTiered = "tiered"
+	CacheNone   = "none"
+)
+
+// Cache configures the cache of songs. Entries expire after TTL, 0 keeps them until they are invalidated,
+// removed entries can not be cached again for TombstoneTTL. WarmupSize most requested songs are cached
+// in background on start, 0 disables warmup. The local cache holds at most LocalSize songs for LocalTTL,
+// in tiered mode it is not invalidated by other instances, so LocalTTL bounds staleness.
+// Zero PoolSize and PoolTimeout leave the defaults of the Redis client.
 type Cache struct {
-	Address string `env:"CACHE_ADDRESS" env-default:"localhost:6379"`
+	Mode         string        `env:"CACHE_MODE" env-default:"redis"`
+	Address      string        `env:"CACHE_ADDRESS" env-default:"localhost:6379"`
+	Username     string        `env:"CACHE_USERNAME"`
+	Password     string        `env:"CACHE_PASSWORD"`
+	DB           int           `env:"CACHE_DB" env-default:"0"`
+	TLS          bool          `env:"CACHE_TLS" env-default:"false"`
+	PoolSize     int           `env:"CACHE_POOL_SIZE" env-default:"0"`
+	MinIdleConns int           `env:"CACHE_MIN_IDLE_CONNS" env-default:"0"`
+	PoolTimeout  time.Duration `env:"CACHE_POOL_TIMEOUT" env-default:"0s"`
+	TTL          time.Duration `env:"CACHE_TTL" env-default:"1h"`
+	TombstoneTTL time.Duration `env:"CACHE_TOMBSTONE_TTL" env-default:"10s"`
+	WarmupSize   int           `env:"CACHE_WARMUP_SIZE" env-default:"100"`
+	LocalSize    int           `env:"CACHE_LOCAL_SIZE" env-default:"10000"`
+	LocalTTL     time.Duration `env:"CACHE_LOCAL_TTL" env-default:"1m"`
+}
+
+// ExternalAPI configures the client of the external API providing details of songs.
+// In DegradedMode songs are saved without details when the external API fails and are enriched later.
+type ExternalAPI struct {
+	URL              string        `env:"EXTERNAL_API_URL" env-default:"http://127.0.0.1:8080"`
+	Timeout          time.Duration `env:"EXTERNAL_API_TIMEOUT" env-default:"10s"`
+	Retries          int           `env:"EXTERNAL_API_RETRIES" env-default:"2"`
+	RetryMinDelay    time.Duration `env:"EXTERNAL_API_RETRY_MIN_DELAY" env-default:"200ms"`
+	RetryMaxDelay    time.Duration `env:"EXTERNAL_API_RETRY_MAX_DELAY" env-default:"2s"`
+	BreakerThreshold int           `env:"EXTERNAL_API_BREAKER_THRESHOLD" env-default:"5"`
+	BreakerCooldown  time.Duration `env:"EXTERNAL_API_BREAKER_COOLDOWN" env-default:"30s"`
+	DegradedMode     bool          `env:"EXTERNAL_API_DEGRADED_MODE" env-default:"false"`
+}
+
+// Jobs configures workers enriching songs added asynchronously.
+type Jobs struct {
+	Workers      int           `env:"JOBS_WORKERS" env-default:"4"`
+	PollInterval time.Duration `env:"JOBS_POLL_INTERVAL" env-default:"1s"`
+	Lease        time.Duration `env:"JOBS_LEASE" env-default:"1m"`
+	MinBackoff   time.Duration `env:"JOBS_MIN_BACKOFF" env-default:"5s"`
+	MaxBackoff   time.Duration `env:"JOBS_MAX_BACKOFF" env-default:"5m"`
+}
+
+// LogValue logs the config with secrets redacted. Nested structs are resolved here,
+// handlers do not look for LogValue of struct fields.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("env", c.Env),
+		slog.Any("database", c.Database),
+		slog.Any("http_server", c.HTTPServer),
+		slog.Any("cache", c.Cache),
+		slog.Any("jobs", c.Jobs),
+		slog.Any("external_api", c.ExternalAPI),
+	)
+}
+
+func (d Database) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", d.Host),
+		slog.String("name", d.Name),
+		slog.String("user", d.User),
+		slog.String("password", redact(d.Password)),
+	)
+}
+
+func (c Cache) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("mode", c.Mode),
+		slog.String("address", c.Address),
+		slog.String("username", c.Username),
+		slog.String("password", redact(c.Password)),
+		slog.Int("db", c.DB),
+		slog.Bool("tls", c.TLS),
+		slog.Int("pool_size", c.PoolSize),
+		slog.Int("min_idle_conns", c.MinIdleConns),
+		slog.Duration("pool_timeout", c.PoolTimeout),
+		slog.Duration("ttl", c.TTL),
+		slog.Duration("tombstone_ttl", c.TombstoneTTL),
+		slog.Int("warmup_size", c.WarmupSize),
+		slog.Int("local_size", c.LocalSize),
+		slog.Duration("local_ttl", c.LocalTTL),
+	)
+}
+
+// redact hides the secret, only whether it is set is logged.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "[REDACTED]"
 }
 
 func MustLoad() *Config {
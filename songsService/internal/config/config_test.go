@@ -0,0 +1,36 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogValueRedactsSecrets(t *testing.T) {
+	cfg := &Config{
+		Env:      "local",
+		Database: Database{Host: "db:5432", Name: "songs", User: "postgres", Password: "db-secret"},
+		Cache:    Cache{Mode: CacheRedis, Address: "redis:6379", Username: "songs", Password: "redis-secret"},
+	}
+
+	for name, handler := range map[string]func(*bytes.Buffer) slog.Handler{
+		"text": func(buf *bytes.Buffer) slog.Handler { return slog.NewTextHandler(buf, nil) },
+		"json": func(buf *bytes.Buffer) slog.Handler { return slog.NewJSONHandler(buf, nil) },
+	} {
+		var buf bytes.Buffer
+		slog.New(handler(&buf)).Info("app was started", slog.Any("config", cfg))
+		out := buf.String()
+
+		for _, secret := range []string{"db-secret", "redis-secret"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s log contains %s: %s", name, secret, out)
+			}
+		}
+		for _, value := range []string{"db:5432", "redis:6379", "[REDACTED]"} {
+			if !strings.Contains(out, value) {
+				t.Errorf("%s log does not contain %s: %s", name, value, out)
+			}
+		}
+	}
+}
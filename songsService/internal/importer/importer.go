@@ -0,0 +1,296 @@
+// Package importer imports songs from CSV and NDJSON files in batches.
+// Rows are validated, songs without text, link or release date are enriched by the external API,
+// and every row gets its own result, so a broken row never fails the whole import.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"log/slog"
+	"reflect"
+	tp "rest/internal/lib/timeParser"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultConcurrency = 8
+	DefaultBatchSize   = 1000
+)
+
+const (
+	StatusImported = "imported"
+	StatusFailed   = "failed"
+)
+
+type SongsImporter interface {
+	// ImportSongs inserts the songs and returns ids of inserted songs by line,
+	// songs that already exist are skipped.
+	ImportSongs(ctx context.Context, rows []models.ImportRowDTO) (map[int]int64, error)
+}
+
+// InfoGetter returns text, link and release date of the song.
+type InfoGetter interface {
+	GetInfo(ctx context.Context, groupName string, songName string) (*models.SongDTO, error)
+}
+
+type Options struct {
+	// Concurrency limits the number of simultaneous requests to the external API.
+	Concurrency int
+	// BatchSize is the number of rows inserted at once.
+	BatchSize int
+}
+
+type Report struct {
+	Total    int         `json:"total"`
+	Imported int         `json:"imported"`
+	Failed   int         `json:"failed"`
+	Rows     []RowResult `json:"rows"`
+}
+
+type RowResult struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	Id     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Importer struct {
+	log        *slog.Logger
+	store      SongsImporter
+	infoGetter InfoGetter
+	validate   *validator.Validate
+	opts       Options
+}
+
+func New(log *slog.Logger, store SongsImporter, infoGetter InfoGetter, opts Options) *Importer {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	validate := validator.New()
+	// report fields by their names in the file
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.Split(field.Tag.Get("json"), ",")[0]
+	})
+
+	return &Importer{
+		log:        log,
+		store:      store,
+		infoGetter: infoGetter,
+		validate:   validate,
+		opts:       opts,
+	}
+}
+
+// Import reads the whole file and imports it batch by batch.
+// An error is returned only when the file can not be read any further or the import is cancelled,
+// the report then contains results of the rows processed so far.
+func (im *Importer) Import(ctx context.Context, r io.Reader, format Format) (*Report, error) {
+	const op = "importer.Import"
+
+	report := &Report{Rows: []RowResult{}}
+
+	reader, err := newRowReader(r, format)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+
+	batch := make([]models.ImportRowDTO, 0, im.opts.BatchSize)
+	for {
+		line, row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *rowError
+		switch {
+		case errors.As(err, &rowErr):
+			report.add(RowResult{Line: line, Status: StatusFailed, Error: rowErr.err.Error()})
+			continue
+		case err != nil:
+			im.flush(ctx, batch, report)
+			report.sort()
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+
+		song, err := im.parseRow(row)
+		if err != nil {
+			report.add(RowResult{Line: line, Status: StatusFailed, Error: err.Error()})
+			continue
+		}
+
+		batch = append(batch, models.ImportRowDTO{Line: line, Song: song})
+		if len(batch) == im.opts.BatchSize {
+			im.flush(ctx, batch, report)
+			batch = batch[:0]
+		}
+		if err = ctx.Err(); err != nil {
+			report.sort()
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	im.flush(ctx, batch, report)
+	report.sort()
+
+	im.log.Info("songs were imported",
+		slog.String("op", op),
+		slog.Int("total", report.Total),
+		slog.Int("imported", report.Imported),
+		slog.Int("failed", report.Failed),
+	)
+
+	return report, nil
+}
+
+func (im *Importer) parseRow(row Row) (models.SongDTO, error) {
+	row.GroupName = strings.TrimSpace(row.GroupName)
+	row.SongName = strings.TrimSpace(row.SongName)
+	row.ReleaseDate = strings.TrimSpace(row.ReleaseDate)
+
+	if err := im.validate.Struct(row); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			messages := make([]string, 0, len(validationErrs))
+			for _, fieldErr := range validationErrs {
+				messages = append(messages, fmt.Sprintf("field %s failed on %s", fieldErr.Field(), fieldErr.Tag()))
+			}
+			return models.SongDTO{}, errors.New(strings.Join(messages, "; "))
+		}
+		return models.SongDTO{}, err
+	}
+
+	song := models.SongDTO{
+		GroupName: row.GroupName,
+		SongName:  row.SongName,
+		Text:      row.Text,
+		Link:      row.Link,
+	}
+	if row.ReleaseDate != "" {
+		date, err := tp.ParseToDate(row.ReleaseDate)
+		if err != nil {
+			return models.SongDTO{}, err
+		}
+		song.ReleaseDate = date
+	}
+
+	return song, nil
+}
+
+// flush enriches and inserts the batch, results of all its rows are added to the report.
+func (im *Importer) flush(ctx context.Context, batch []models.ImportRowDTO, report *Report) {
+	const op = "importer.flush"
+
+	if len(batch) == 0 {
+		return
+	}
+
+	failed := im.enrich(ctx, batch)
+
+	rows := make([]models.ImportRowDTO, 0, len(batch))
+	for i := range batch {
+		if failed[i] == nil {
+			rows = append(rows, batch[i])
+		}
+	}
+
+	var ids map[int]int64
+	var insertErr error
+	if len(rows) != 0 {
+		ids, insertErr = im.store.ImportSongs(ctx, rows)
+		if insertErr != nil {
+			im.log.Error("unable to import batch", slog.String("op", op), slog.String("error", insertErr.Error()))
+		}
+	}
+
+	for i, row := range batch {
+		result := RowResult{Line: row.Line, Status: StatusFailed}
+		switch id, ok := ids[row.Line]; {
+		case failed[i] != nil:
+			result.Error = failed[i].Error()
+		case insertErr != nil:
+			result.Error = insertErrorMessage(insertErr)
+		case !ok:
+			result.Error = storage.ErrSongExists.Error()
+		default:
+			result.Status, result.Id = StatusImported, id
+		}
+		report.add(result)
+	}
+}
+
+// enrich fills missing fields of the songs in place and returns errors of the rows that could not be enriched.
+func (im *Importer) enrich(ctx context.Context, batch []models.ImportRowDTO) []error {
+	errs := make([]error, len(batch))
+	sem := make(chan struct{}, im.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := range batch {
+		song := &batch[i].Song
+		if song.Text != "" && song.Link != "" && !song.ReleaseDate.IsZero() {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			info, err := im.infoGetter.GetInfo(ctx, song.GroupName, song.SongName)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to get song info: %w", err)
+				return
+			}
+			if song.Text == "" {
+				song.Text = info.Text
+			}
+			if song.Link == "" {
+				song.Link = info.Link
+			}
+			if song.ReleaseDate.IsZero() {
+				song.ReleaseDate = info.ReleaseDate
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func (r *Report) add(result RowResult) {
+	r.Total++
+	if result.Status == StatusImported {
+		r.Imported++
+	} else {
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, result)
+}
+
+// sort orders results by line, rows failed while reading are reported before their batch is inserted.
+func (r *Report) sort() {
+	slices.SortStableFunc(r.Rows, func(a, b RowResult) int {
+		return a.Line - b.Line
+	})
+}
+
+// insertErrorMessage hides details of unexpected storage errors from the report.
+func insertErrorMessage(err error) string {
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return storageErr.Msg
+	}
+	return "failed to save song"
+}
@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown import format, expected csv or ndjson")
+	ErrInvalidHeader = errors.New("invalid csv header")
+)
+
+// maxLineSize limits the size of a NDJSON line, lyrics are the only long field.
+const maxLineSize = 1 << 20
+
+// ParseFormat parses the name of the format or the media type of the file.
+func ParseFormat(s string) (Format, error) {
+	if mediaType, _, err := mime.ParseMediaType(s); err == nil {
+		s = mediaType
+	}
+
+	switch strings.ToLower(s) {
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl", "application/x-ndjson", "application/jsonl", "application/jsonlines":
+		return FormatNDJSON, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// Row is a song as it is written in the imported file.
+type Row struct {
+	GroupName   string `json:"group_name" validate:"required,max=255"`
+	SongName    string `json:"song_name" validate:"required,max=255"`
+	ReleaseDate string `json:"release_date" validate:"omitempty,datetime=2006-01-02"`
+	Text        string `json:"text"`
+	Link        string `json:"link" validate:"omitempty,url"`
+}
+
+// rowReader reads rows one by one. Errors of single rows are returned as *rowError,
+// the reading may continue after them; other errors abort the import. Reading ends with io.EOF.
+type rowReader interface {
+	Next() (line int, row Row, err error)
+}
+
+type rowError struct {
+	line int
+	err  error
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+func newRowReader(r io.Reader, format Format) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// csvReader reads CSV files with a header naming the columns,
+// group_name and song_name columns are required, release_date, text and link are optional.
+type csvReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidHeader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch column {
+		case "group_name", "song_name", "release_date", "text", "link":
+		default:
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidHeader, column)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidHeader, column)
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	if !seen["group_name"] || !seen["song_name"] {
+		return nil, fmt.Errorf("%w: group_name and song_name columns are required", ErrInvalidHeader)
+	}
+
+	reader.FieldsPerRecord = len(header)
+	return &csvReader{reader: reader, columns: header}, nil
+}
+
+func (c *csvReader) Next() (int, Row, error) {
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, Row{}, io.EOF
+	}
+
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, Row{}, &rowError{line: parseErr.StartLine, err: parseErr.Err}
+		}
+		return 0, Row{}, err
+	}
+	line, _ := c.reader.FieldPos(0)
+
+	var row Row
+	for i, value := range record {
+		switch c.columns[i] {
+		case "group_name":
+			row.GroupName = value
+		case "song_name":
+			row.SongName = value
+		case "release_date":
+			row.ReleaseDate = value
+		case "text":
+			row.Text = value
+		case "link":
+			row.Link = value
+		}
+	}
+
+	return line, row, nil
+}
+
+// ndjsonReader reads files with one JSON object per line, blank lines are skipped.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonReader) Next() (int, Row, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var row Row
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			return n.line, Row{}, &rowError{line: n.line, err: err}
+		}
+		return n.line, row, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return n.line + 1, Row{}, err
+	}
+	return 0, Row{}, io.EOF
+}
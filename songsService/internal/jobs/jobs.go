@@ -0,0 +1,237 @@
+// Package jobs enriches songs added asynchronously with details from the external API.
+// Jobs are stored in the database, so they survive restarts and may be processed by several instances.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"rest/internal/clients/info"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWorkers      = 4
+	DefaultPollInterval = time.Second
+	DefaultLease        = time.Minute
+	DefaultMinBackoff   = 5 * time.Second
+	DefaultMaxBackoff   = 5 * time.Minute
+)
+
+type Store interface {
+	ClaimJob(ctx context.Context, lease time.Duration) (*models.JobDTO, error)
+	CompleteJob(ctx context.Context, id int64, attempt int) error
+	FailJob(ctx context.Context, id int64, attempt int, reason string, retry bool, delay time.Duration) error
+	GetSong(ctx context.Context, id int64) (*models.SongDTO, error)
+	UpdateSong(ctx context.Context, id int64, version int64, set *models.SetBuilder) (*models.SongDTO, error)
+}
+
+// InfoGetter returns details of the song, only text, release date and link of the result are used.
+type InfoGetter interface {
+	GetInfo(ctx context.Context, groupName string, songName string) (*models.SongDTO, error)
+}
+
+// Options of the pool, zero values are replaced with defaults.
+// Lease is the time a worker has to process a job before another worker takes it over.
+// Failed attempts are retried after MinBackoff doubled with every attempt up to MaxBackoff.
+type Options struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// Pool is a pool of workers processing jobs.
+type Pool struct {
+	log        *slog.Logger
+	store      Store
+	infoGetter InfoGetter
+	opts       Options
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(log *slog.Logger, store Store, infoGetter InfoGetter, opts Options) *Pool {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = DefaultLease
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(DefaultMaxBackoff, opts.MinBackoff)
+	}
+
+	return &Pool{
+		log:        log,
+		store:      store,
+		infoGetter: infoGetter,
+		opts:       opts,
+	}
+}
+
+// Start starts the workers, they run until Stop is called or ctx is done.
+func (p *Pool) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	for range p.opts.Workers {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work(ctx)
+		}()
+	}
+}
+
+// Stop stops the workers and waits for them to return. Jobs interrupted by Stop are retried after their lease.
+func (p *Pool) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	const op = "jobs.work"
+
+	for {
+		job, err := p.store.ClaimJob(ctx, p.opts.Lease)
+		if err != nil && ctx.Err() == nil {
+			p.log.Error("failed to claim job", slog.String("op", op), slog.String("error", err.Error()))
+		}
+
+		// jobs are taken one after another while there are any
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.opts.PollInterval):
+			}
+			continue
+		}
+
+		p.processSafely(ctx, job)
+	}
+}
+
+// processSafely keeps the worker alive when the job panics,
+// the job stays running and is retried after its lease like a job of a crashed worker.
+func (p *Pool) processSafely(ctx context.Context, job *models.JobDTO) {
+	const op = "jobs.processSafely"
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.log.Error("job panicked",
+				slog.String("op", op),
+				slog.Int64("job_id", job.Id),
+				slog.Int64("song_id", job.SongId),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())),
+			)
+		}
+	}()
+
+	p.process(ctx, job)
+}
+
+// process runs the job and records its outcome.
+func (p *Pool) process(ctx context.Context, job *models.JobDTO) {
+	const op = "jobs.process"
+
+	log := p.log.With(slog.String("op", op), slog.Int64("job_id", job.Id), slog.Int64("song_id", job.SongId))
+
+	err := p.run(ctx, job)
+	if err == nil {
+		err = p.store.CompleteJob(ctx, job.Id, job.Attempts)
+		if errors.Is(err, storage.ErrJobLeaseLost) {
+			log.Warn("job was taken over by another worker, the result is discarded")
+			return
+		}
+		if err != nil {
+			log.Error("failed to complete job", slog.String("error", err.Error()))
+			return
+		}
+		log.Debug("job was completed")
+		return
+	}
+
+	// a deleted song can not be enriched, its job is deleted with it unless the deletion is racing,
+	// and the external API gives the same answer when it has rejected the song
+	retry := !errors.Is(err, storage.ErrSongNotFound) && !errors.Is(err, info.ErrClient)
+	delay := p.backoff(job.Attempts)
+	log.Warn("job attempt failed",
+		slog.Int("attempt", job.Attempts),
+		slog.Bool("retry", retry),
+		slog.Duration("retry_in", delay),
+		slog.String("error", err.Error()),
+	)
+	err = p.store.FailJob(ctx, job.Id, job.Attempts, err.Error(), retry, delay)
+	if errors.Is(err, storage.ErrJobLeaseLost) {
+		log.Warn("job was taken over by another worker, the failure is discarded")
+		return
+	}
+	if err != nil {
+		log.Error("failed to record job failure", slog.String("error", err.Error()))
+	}
+}
+
+// run fills the details of the song which are still empty, details edited by clients are kept.
+func (p *Pool) run(ctx context.Context, job *models.JobDTO) error {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Lease)
+	defer cancel()
+
+	song, err := p.store.GetSong(ctx, job.SongId)
+	if err != nil {
+		return err
+	}
+
+	details, err := p.infoGetter.GetInfo(ctx, song.GroupName, song.SongName)
+	if err != nil {
+		return err
+	}
+
+	set := models.Set()
+	if song.Text == "" && details.Text != "" {
+		set.Value(models.ColumnText, details.Text)
+	}
+	if song.Link == "" && details.Link != "" {
+		set.Value(models.ColumnLink, details.Link)
+	}
+	if song.ReleaseDate.IsZero() && !details.ReleaseDate.IsZero() {
+		set.Value(models.ColumnReleaseDate, details.ReleaseDate)
+	}
+	if set.Empty() {
+		return nil
+	}
+
+	// the song may be edited meanwhile, the next attempt sees the edit
+	_, err = p.store.UpdateSong(ctx, job.SongId, song.Version, set)
+	if err != nil {
+		if errors.Is(err, storage.ErrVersionMismatch) {
+			return errors.New("song was modified during enrichment")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the next attempt, it doubles with every failed attempt.
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.opts.MinBackoff
+	for i := 1; i < attempt && delay < p.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.opts.MaxBackoff)
+}
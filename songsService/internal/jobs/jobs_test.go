@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"rest/pkg/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// queueStore hands out the queued jobs and records completed ones.
+type queueStore struct {
+	mu        sync.Mutex
+	jobs      []*models.JobDTO
+	completed []int64
+}
+
+func (s *queueStore) ClaimJob(context.Context, time.Duration) (*models.JobDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return nil, nil
+	}
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+	job.Attempts++
+	return job, nil
+}
+
+func (s *queueStore) CompleteJob(_ context.Context, id int64, _ int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, id)
+	return nil
+}
+
+func (s *queueStore) FailJob(context.Context, int64, int, string, bool, time.Duration) error {
+	return nil
+}
+
+// GetSong returns complete songs, the song with id 1 is by the group the info getter panics on.
+func (s *queueStore) GetSong(_ context.Context, id int64) (*models.SongDTO, error) {
+	groupName := "Muse"
+	if id == 1 {
+		groupName = "panic"
+	}
+	return &models.SongDTO{Id: id, GroupName: groupName, SongName: "Hysteria", Text: "text", Link: "link",
+		ReleaseDate: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+func (s *queueStore) UpdateSong(context.Context, int64, int64, *models.SetBuilder) (*models.SongDTO, error) {
+	return nil, nil
+}
+
+func (s *queueStore) completedJobs() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.completed...)
+}
+
+// panickingInfo panics for songs of the group "panic".
+type panickingInfo struct{}
+
+func (panickingInfo) GetInfo(_ context.Context, groupName string, songName string) (*models.SongDTO, error) {
+	if groupName == "panic" {
+		panic("boom")
+	}
+	return &models.SongDTO{GroupName: groupName, SongName: songName}, nil
+}
+
+func TestWorkerSurvivesPanickingJob(t *testing.T) {
+	store := &queueStore{jobs: []*models.JobDTO{{Id: 1, SongId: 1}, {Id: 2, SongId: 2}}}
+	pool := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, panickingInfo{},
+		Options{Workers: 1, PollInterval: time.Millisecond})
+
+	pool.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for len(store.completedJobs()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	pool.Stop()
+
+	completed := store.completedJobs()
+	if len(completed) != 1 || completed[0] != 2 {
+		t.Errorf("completed jobs %v, want [2]", completed)
+	}
+}
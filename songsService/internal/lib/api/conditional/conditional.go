@@ -0,0 +1,85 @@
+// Package conditional implements validators of HTTP conditional requests (RFC 9110, section 13).
+package conditional
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingIfMatch = errors.New("If-Match header is required, use ETag of the current representation")
+	ErrInvalidIfMatch = errors.New("If-Match header must contain a single strong entity tag or *")
+)
+
+// VersionETag returns the strong entity tag of the given version of a resource.
+func VersionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// IfMatchVersion returns the version required by If-Match header, 0 stands for any version ("*").
+func IfMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, ErrMissingIfMatch
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	// If-Match uses the strong comparison, so weak tags never match
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+// SetValidators writes ETag and Last-Modified headers, zero values are skipped.
+func SetValidators(w http.ResponseWriter, etag string, modified time.Time) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// NotModified reports whether the client already has the current representation.
+// If-Modified-Since is only evaluated when the request has no If-None-Match.
+func NotModified(r *http.Request, etag string, modified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return matchETag(header, etag)
+	}
+
+	header := r.Header.Get("If-Modified-Since")
+	if header == "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	// Last-Modified has second precision
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// matchETag checks the etag against a comma-separated list of entity tags using the weak comparison.
+func matchETag(header string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package response
 import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
+	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -43,3 +45,40 @@ func ValidationError(errs validator.ValidationErrors) Response {
 		Error:  strings.Join(errMessages, "; "),
 	}
 }
+
+// Pagination is the envelope of list responses.
+// Next and Prev are links to the neighbouring pages keeping all other query parameters of the request.
+type Pagination struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Pages      int    `json:"pages"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+func NewPagination(total int, page int, limit int) Pagination {
+	return Pagination{
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Pages: (total + limit - 1) / limit,
+	}
+}
+
+// Link returns the request URL with replaced query parameters, empty values remove parameters.
+func Link(r *http.Request, params map[string]string) string {
+	query := r.URL.Query()
+	for key, value := range params {
+		if value == "" {
+			query.Del(key)
+			continue
+		}
+		query.Set(key, value)
+	}
+
+	link := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return link.String()
+}
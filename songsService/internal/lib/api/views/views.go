@@ -0,0 +1,214 @@
+// Package views contains the JSON representation of the storage models in the API.
+// Handlers never render storage models directly, they map them to views first.
+package views
+
+import (
+	"encoding/json"
+	"rest/internal/lib/breaker"
+	tp "rest/internal/lib/timeParser"
+	"rest/pkg/models"
+	"time"
+)
+
+// Date is rendered as YYYY-MM-DD, zero date is rendered as null.
+type Date time.Time
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if time.Time(d).IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(tp.FormatDate(time.Time(d)))
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	date, err := tp.ParseToDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Date(date)
+	return nil
+}
+
+type Song struct {
+	Id          int64  `json:"id"`
+	GroupName   string `json:"group_name"`
+	SongName    string `json:"song_name"`
+	ReleaseDate Date   `json:"release_date" swaggertype:"string" format:"date" example:"2006-07-16"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+func FromSong(song *models.SongDTO) Song {
+	return Song{
+		Id:          song.Id,
+		GroupName:   song.GroupName,
+		SongName:    song.SongName,
+		ReleaseDate: Date(song.ReleaseDate),
+		Text:        song.Text,
+		Link:        song.Link,
+	}
+}
+
+func FromSongs(songs []models.SongDTO) []Song {
+	result := make([]Song, 0, len(songs))
+	for i := range songs {
+		result = append(result, FromSong(&songs[i]))
+	}
+	return result
+}
+
+func (s *Song) ToDTO() models.SongDTO {
+	return models.SongDTO{
+		Id:          s.Id,
+		GroupName:   s.GroupName,
+		SongName:    s.SongName,
+		ReleaseDate: time.Time(s.ReleaseDate),
+		Text:        s.Text,
+		Link:        s.Link,
+	}
+}
+
+// Fields returns only the given fields of the song keyed by their names in the API.
+func (s *Song) Fields(fields []models.Column) map[string]any {
+	result := make(map[string]any, len(fields))
+	for _, field := range fields {
+		var value any
+		switch field {
+		case models.ColumnId:
+			value = s.Id
+		case models.ColumnGroupName:
+			value = s.GroupName
+		case models.ColumnSongName:
+			value = s.SongName
+		case models.ColumnReleaseDate:
+			value = s.ReleaseDate
+		case models.ColumnText:
+			value = s.Text
+		case models.ColumnLink:
+			value = s.Link
+		}
+		result[models.FieldName(field)] = value
+	}
+	return result
+}
+
+type Artist struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	Songs []Song `json:"songs,omitempty"`
+}
+
+func FromArtist(artist *models.ArtistDTO) Artist {
+	result := Artist{Id: artist.Id, Name: artist.Name}
+	if artist.Songs != nil {
+		result.Songs = FromSongs(artist.Songs)
+	}
+	return result
+}
+
+func FromArtists(artists []models.ArtistDTO) []Artist {
+	result := make([]Artist, 0, len(artists))
+	for i := range artists {
+		result = append(result, FromArtist(&artists[i]))
+	}
+	return result
+}
+
+type Album struct {
+	Id          int64   `json:"id"`
+	Title       string  `json:"title"`
+	GroupName   string  `json:"group_name"`
+	ReleaseDate Date    `json:"release_date" swaggertype:"string" format:"date" example:"2006-07-16"`
+	CoverLink   string  `json:"cover_link"`
+	Tracks      []Track `json:"tracks"`
+}
+
+type Track struct {
+	Number int `json:"track_number"`
+	Song
+}
+
+func FromAlbum(album *models.AlbumDTO) Album {
+	result := Album{
+		Id:          album.Id,
+		Title:       album.Title,
+		GroupName:   album.GroupName,
+		ReleaseDate: Date(album.ReleaseDate),
+		CoverLink:   album.CoverLink,
+		Tracks:      make([]Track, 0, len(album.Tracks)),
+	}
+	for i := range album.Tracks {
+		result.Tracks = append(result.Tracks, Track{
+			Number: album.Tracks[i].Number,
+			Song:   FromSong(&album.Tracks[i].Song),
+		})
+	}
+	return result
+}
+
+type SearchResult struct {
+	Song
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func FromSearchResults(results []models.SearchResultDTO) []SearchResult {
+	result := make([]SearchResult, 0, len(results))
+	for i := range results {
+		result = append(result, SearchResult{
+			Song:    FromSong(&results[i].Song),
+			Rank:    results[i].Rank,
+			Snippet: results[i].Snippet,
+		})
+	}
+	return result
+}
+
+type Job struct {
+	Id        int64     `json:"id"`
+	SongId    int64     `json:"song_id"`
+	Status    string    `json:"status" enums:"pending,running,done,failed"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func FromJob(job *models.JobDTO) Job {
+	return Job{
+		Id:        job.Id,
+		SongId:    job.SongId,
+		Status:    job.Status,
+		Attempts:  job.Attempts,
+		Error:     job.LastError,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
+
+// Breaker is the state of a circuit breaker, retry_at is the time the open breaker lets a probe call through.
+type Breaker struct {
+	State    string     `json:"state" enums:"closed,open,half-open"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+	RetryAt  *time.Time `json:"retry_at,omitempty"`
+}
+
+func FromBreaker(snapshot breaker.Snapshot) Breaker {
+	result := Breaker{State: string(snapshot.State), Failures: snapshot.Failures}
+	if !snapshot.OpenedAt.IsZero() {
+		result.OpenedAt = &snapshot.OpenedAt
+		result.RetryAt = &snapshot.RetryAt
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+// Package breaker implements a circuit breaker which stops calls to a failing dependency.
+// After Threshold consecutive failures the breaker opens and fails calls fast for Cooldown,
+// then it half-opens and lets a single probe call through, the outcome of the probe closes or reopens it.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half-open"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Snapshot is the state of the breaker at some moment, RetryAt is set while the breaker is open.
+type Snapshot struct {
+	State    State
+	Failures int
+	OpenedAt time.Time
+	RetryAt  time.Time
+}
+
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     Closed,
+	}
+}
+
+// Allow returns ErrOpen when the call must not be made. Every allowed call must be followed
+// by Success, Failure or Release, otherwise the half-open breaker never lets another probe through.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open {
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		b.state = HalfOpen
+		b.probing = false
+	}
+	if b.state == HalfOpen {
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// Success records the successful call and closes the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records the failed call, the breaker opens after threshold failures in a row or a failed probe.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Release records the call which tells nothing about the dependency, e.g. the one cancelled by the caller.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := Snapshot{State: b.state, Failures: b.failures}
+	if b.state == Open {
+		snapshot.OpenedAt = b.openedAt
+		snapshot.RetryAt = b.openedAt.Add(b.cooldown)
+		// the breaker half-opens lazily on the next call
+		if !b.now().Before(snapshot.RetryAt) {
+			snapshot.State = HalfOpen
+		}
+	}
+
+	return snapshot
+}
@@ -0,0 +1,130 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// clock is the time of the breaker under test, it moves only when advanced.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := New(threshold, cooldown)
+	b.now = c.Now
+	return b, c
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		calls     []bool // outcomes of the calls, true is a success
+		state     State
+	}{
+		{"no calls", 3, nil, Closed},
+		{"failures below threshold", 3, []bool{false, false}, Closed},
+		{"failures reach threshold", 3, []bool{false, false, false}, Open},
+		{"success resets failures", 3, []bool{false, false, true, false, false}, Closed},
+		{"zero threshold opens on the first failure", 0, []bool{false}, Open},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newTestBreaker(tt.threshold, time.Minute)
+			for i, success := range tt.calls {
+				if err := b.Allow(); err != nil {
+					t.Fatalf("call %d: Allow() error = %v", i, err)
+				}
+				if success {
+					b.Success()
+				} else {
+					b.Failure()
+				}
+			}
+
+			if state := b.Snapshot().State; state != tt.state {
+				t.Errorf("state = %s, want %s", state, tt.state)
+			}
+			wantErr := error(nil)
+			if tt.state == Open {
+				wantErr = ErrOpen
+			}
+			if err := b.Allow(); !errors.Is(err, wantErr) {
+				t.Errorf("Allow() error = %v, want %v", err, wantErr)
+			}
+		})
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome func(b *Breaker)
+		state   State
+	}{
+		{"successful probe closes", (*Breaker).Success, Closed},
+		{"failed probe reopens", (*Breaker).Failure, Open},
+		{"released probe lets another probe through", (*Breaker).Release, HalfOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, c := newTestBreaker(1, time.Minute)
+			_ = b.Allow()
+			b.Failure()
+
+			c.Advance(time.Minute - time.Nanosecond)
+			if err := b.Allow(); !errors.Is(err, ErrOpen) {
+				t.Fatalf("Allow() during cooldown error = %v, want %v", err, ErrOpen)
+			}
+
+			c.Advance(time.Nanosecond)
+			if state := b.Snapshot().State; state != HalfOpen {
+				t.Fatalf("state after cooldown = %s, want %s", state, HalfOpen)
+			}
+			if err := b.Allow(); err != nil {
+				t.Fatalf("probe Allow() error = %v", err)
+			}
+			// exactly one probe is in flight
+			if err := b.Allow(); !errors.Is(err, ErrOpen) {
+				t.Fatalf("second probe Allow() error = %v, want %v", err, ErrOpen)
+			}
+
+			tt.outcome(b)
+			if state := b.Snapshot().State; state != tt.state {
+				t.Errorf("state after probe = %s, want %s", state, tt.state)
+			}
+		})
+	}
+}
+
+func TestBreakerReopensForFullCooldown(t *testing.T) {
+	b, c := newTestBreaker(1, time.Minute)
+	_ = b.Allow()
+	b.Failure()
+
+	c.Advance(time.Minute)
+	_ = b.Allow()
+	b.Failure()
+
+	snapshot := b.Snapshot()
+	if !snapshot.OpenedAt.Equal(c.Now()) || !snapshot.RetryAt.Equal(c.Now().Add(time.Minute)) {
+		t.Errorf("snapshot = %+v, want opened now for a minute", snapshot)
+	}
+	c.Advance(time.Minute - time.Nanosecond)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Allow() error = %v, want %v", err, ErrOpen)
+	}
+}
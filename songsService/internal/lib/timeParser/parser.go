@@ -2,7 +2,13 @@ package timeParser
 
 import "time"
 
+// DateLayout is the format of dates in the API.
+const DateLayout = "2006-01-02"
+
 func ParseToDate(date string) (time.Time, error) {
-	const layout = "2006-01-02"
-	return time.Parse(layout, date)
+	return time.Parse(DateLayout, date)
+}
+
+func FormatDate(date time.Time) string {
+	return date.Format(DateLayout)
 }
@@ -0,0 +1,104 @@
+package add
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	tp "rest/internal/lib/timeParser"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/albums"
+	"rest/pkg/models"
+	"time"
+)
+
+type AlbumSaver interface {
+	AddAlbum(ctx context.Context, albumDTO *models.AlbumDTO) (int64, error)
+}
+
+type Request struct {
+	Title       string `json:"title" validate:"required"`
+	GroupName   string `json:"group_name" validate:"required"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	CoverLink   string `json:"cover_link,omitempty" validate:"omitempty,url"`
+}
+
+type Response struct {
+	response.Response
+	Id int64 `json:"id"`
+}
+
+// @Summary Add a new album
+// @Description Add a new album of the group, the group is created if it does not exist yet.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param request body Request true "Album details"
+// @Success 201 {object} Response "Successful add new album"
+// @Failure 400 {object} response.Response "Bad request error response"
+// @Failure 500 {object} response.Response "Internal server error response"
+// @Router /albums [post]
+func New(ctx context.Context, log *slog.Logger, albumSaver AlbumSaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/albums/add.New"
+
+		var req Request
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error(albums.ErrDecodeRequest.Error(),
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(albums.ErrDecodeRequest.Error()))
+			return
+		}
+
+		log.Debug("request was successfully decoded", slog.String("op", op), slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			var validationErrs validator.ValidationErrors
+			errors.As(err, &validationErrs)
+			log.Error("failed to validate request",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.ValidationError(validationErrs))
+			return
+		}
+
+		var releaseDate time.Time
+		if req.ReleaseDate != "" {
+			var err error
+			releaseDate, err = tp.ParseToDate(req.ReleaseDate)
+			if err != nil {
+				log.Error("failed to parse release date",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid release date"))
+				return
+			}
+		}
+
+		id, err := albumSaver.AddAlbum(ctx, &models.AlbumDTO{
+			Title:       req.Title,
+			GroupName:   req.GroupName,
+			ReleaseDate: releaseDate,
+			CoverLink:   req.CoverLink,
+		})
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, albums.ErrAddAlbum)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{response.OK(), id})
+		return
+	}
+}
@@ -0,0 +1,67 @@
+package get
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/albums"
+	"rest/pkg/models"
+	"strconv"
+)
+
+type AlbumGetter interface {
+	GetAlbum(ctx context.Context, id int64) (*models.AlbumDTO, error)
+}
+
+type Response struct {
+	response.Response
+	Album views.Album `json:"album"`
+}
+
+// @Summary Get album
+// @Description Retrieve an album with its songs in tracklist order.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "Album ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /albums/{id} [get]
+func New(ctx context.Context, log *slog.Logger, albumGetter AlbumGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/albums/get.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(albums.ErrInvalidId.Error()))
+			return
+		}
+
+		album, err := albumGetter.GetAlbum(ctx, id)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, albums.ErrGetAlbum)
+			return
+		}
+
+		log.Debug("album was received", slog.String("op", op), slog.Int64("id", id))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			views.FromAlbum(album),
+		})
+		return
+	}
+}
@@ -0,0 +1,12 @@
+package albums
+
+import "errors"
+
+var (
+	ErrDecodeRequest = errors.New("error decoding request")
+	ErrAddAlbum      = errors.New("error adding album")
+	ErrGetAlbum      = errors.New("error getting album")
+	ErrAttachSongs   = errors.New("error attaching songs to album")
+	ErrReorderTracks = errors.New("error reordering album tracks")
+	ErrInvalidId     = errors.New("invalid album id")
+)
@@ -0,0 +1,86 @@
+package reorder
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/albums"
+	"strconv"
+)
+
+type TracksReorderer interface {
+	ReorderTracks(ctx context.Context, albumId int64, songIds []int64) error
+}
+
+type Request struct {
+	SongIds []int64 `json:"song_ids" validate:"required,min=1,unique"`
+}
+
+// @Summary Reorder album tracks
+// @Description Set the tracklist order, the request must contain every song of the album exactly once.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "Album ID"
+// @Param request body Request true "Songs IDs in the new tracklist order"
+// @Success 204 {object} nil
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /albums/{id}/tracks [put]
+func New(ctx context.Context, log *slog.Logger, tracksReorderer TracksReorderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/albums/reorder.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(albums.ErrInvalidId.Error()))
+			return
+		}
+
+		var req Request
+
+		if err = render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error(albums.ErrDecodeRequest.Error(),
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(albums.ErrDecodeRequest.Error()))
+			return
+		}
+
+		if err = validator.New().Struct(req); err != nil {
+			var validationErrs validator.ValidationErrors
+			errors.As(err, &validationErrs)
+			log.Error("failed to validate request",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.ValidationError(validationErrs))
+			return
+		}
+
+		err = tracksReorderer.ReorderTracks(ctx, id, req.SongIds)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, albums.ErrReorderTracks)
+			return
+		}
+
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, nil)
+		return
+	}
+}
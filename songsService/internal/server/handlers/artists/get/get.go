@@ -0,0 +1,67 @@
+package get
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/artists"
+	"rest/pkg/models"
+	"strconv"
+)
+
+type ArtistGetter interface {
+	GetArtist(ctx context.Context, id int64) (*models.ArtistDTO, error)
+}
+
+type Response struct {
+	response.Response
+	Artist views.Artist `json:"artist"`
+}
+
+// @Summary Get artist
+// @Description Retrieve an artist with all of their songs.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param id path int true "Artist ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /artists/{id} [get]
+func New(ctx context.Context, log *slog.Logger, artistGetter ArtistGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/artists/get.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(artists.ErrInvalidId.Error()))
+			return
+		}
+
+		artist, err := artistGetter.GetArtist(ctx, id)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, artists.ErrGetArtists)
+			return
+		}
+
+		log.Debug("artist was received", slog.String("op", op), slog.Int64("id", id))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			views.FromArtist(artist),
+		})
+		return
+	}
+}
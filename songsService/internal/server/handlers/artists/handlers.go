@@ -0,0 +1,12 @@
+package artists
+
+import "errors"
+
+var (
+	ErrDecodeRequest = errors.New("error decoding request")
+	ErrParseRequest  = errors.New("error parsing request")
+	ErrGetArtists    = errors.New("error getting artists")
+	ErrRenameArtist  = errors.New("error renaming artist")
+	ErrMergeArtists  = errors.New("error merging artists")
+	ErrInvalidId     = errors.New("invalid artist id")
+)
@@ -0,0 +1,97 @@
+package list
+
+import (
+	"context"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/artists"
+	"rest/pkg/models"
+	"strconv"
+)
+
+type ArtistsGetter interface {
+	GetArtists(ctx context.Context, page int, limit int) ([]models.ArtistDTO, error)
+}
+
+type Response struct {
+	response.Response
+	Artists []views.Artist `json:"artists"`
+}
+
+// @Summary Get artists
+// @Description Retrieve artists with pagination.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of results per page" default(10) maximum(100)
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /artists [get]
+func New(ctx context.Context, log *slog.Logger, artistsGetter ArtistsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/artists/list.New"
+
+		page, limit := 1, 10
+
+		err := r.ParseForm()
+		if err != nil {
+			log.Error("unable to parse form", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(artists.ErrParseRequest.Error()))
+			return
+		}
+
+		if r.FormValue("page") != "" {
+			page, err = strconv.Atoi(r.FormValue("page"))
+			if err != nil {
+				log.Error("unable to parse page",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(artists.ErrParseRequest.Error()))
+				return
+			}
+		}
+		if r.FormValue("limit") != "" {
+			limit, err = strconv.Atoi(r.FormValue("limit"))
+			if err != nil {
+				log.Error("unable to parse limit",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(artists.ErrParseRequest.Error()))
+				return
+			}
+		}
+
+		if err = models.ValidatePage(page, limit); err != nil {
+			log.Error("invalid page", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		artistsData, err := artistsGetter.GetArtists(ctx, page, limit)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, artists.ErrGetArtists)
+			return
+		}
+
+		log.Debug("artists were received", slog.String("op", op))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			views.FromArtists(artistsData),
+		})
+		return
+	}
+}
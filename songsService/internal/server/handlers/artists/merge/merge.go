@@ -0,0 +1,92 @@
+package merge
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/artists"
+	"strconv"
+)
+
+type ArtistsMerger interface {
+	MergeArtists(ctx context.Context, targetId int64, sourceIds []int64) error
+}
+
+type Request struct {
+	SourceIds []int64 `json:"source_ids" validate:"required,min=1"`
+}
+
+// @Summary Merge duplicate artists
+// @Description Move all songs of the source artists to the artist from the path and remove the source artists.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param id path int true "Target artist ID"
+// @Param request body Request true "Duplicate artists IDs"
+// @Success 204 {object} nil
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /artists/{id}/merge [post]
+func New(ctx context.Context, log *slog.Logger, artistsMerger ArtistsMerger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/artists/merge.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(artists.ErrInvalidId.Error()))
+			return
+		}
+
+		var req Request
+
+		if err = render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error(artists.ErrDecodeRequest.Error(),
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(artists.ErrDecodeRequest.Error()))
+			return
+		}
+
+		if err = validator.New().Struct(req); err != nil {
+			var validationErrs validator.ValidationErrors
+			errors.As(err, &validationErrs)
+			log.Error("failed to validate request",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.ValidationError(validationErrs))
+			return
+		}
+
+		err = artistsMerger.MergeArtists(ctx, id, req.SourceIds)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, artists.ErrMergeArtists)
+			return
+		}
+
+		log.Info("artists were merged",
+			slog.String("op", op),
+			slog.Int64("target_id", id),
+			slog.Any("source_ids", req.SourceIds),
+		)
+
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, nil)
+		return
+	}
+}
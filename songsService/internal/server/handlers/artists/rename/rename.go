@@ -0,0 +1,87 @@
+package rename
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/artists"
+	"strconv"
+)
+
+type ArtistRenamer interface {
+	RenameArtist(ctx context.Context, id int64, name string) error
+}
+
+type Request struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// @Summary Rename an artist
+// @Description Change the name of an artist, the new name must not belong to another artist.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param id path int true "Artist ID"
+// @Param request body Request true "New artist name"
+// @Success 204 {object} nil
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /artists/{id} [put]
+func New(ctx context.Context, log *slog.Logger, artistRenamer ArtistRenamer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/artists/rename.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(artists.ErrInvalidId.Error()))
+			return
+		}
+
+		var req Request
+
+		if err = render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error(artists.ErrDecodeRequest.Error(),
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(artists.ErrDecodeRequest.Error()))
+			return
+		}
+
+		if err = validator.New().Struct(req); err != nil {
+			var validationErrs validator.ValidationErrors
+			errors.As(err, &validationErrs)
+			log.Error("failed to validate request",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.ValidationError(validationErrs))
+			return
+		}
+
+		err = artistRenamer.RenameArtist(ctx, id, req.Name)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, artists.ErrRenameArtist)
+			return
+		}
+
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, nil)
+		return
+	}
+}
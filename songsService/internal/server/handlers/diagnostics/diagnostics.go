@@ -0,0 +1,44 @@
+package diagnostics
+
+import (
+	"context"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/lib/breaker"
+)
+
+type BreakerStater interface {
+	BreakerState() breaker.Snapshot
+}
+
+type Response struct {
+	response.Response
+	ExternalAPI views.Breaker `json:"external_api"`
+}
+
+// @Summary Get diagnostics
+// @Description Retrieve state of the circuit breaker guarding the external API. While the breaker is open,
+// @Description songs can not be added synchronously, unless the service runs in degraded mode.
+// @Tags diagnostics
+// @Produce json
+// @Success 200 {object} Response
+// @Router /diagnostics [get]
+func New(ctx context.Context, log *slog.Logger, externalAPI BreakerStater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/diagnostics.New"
+
+		snapshot := externalAPI.BreakerState()
+
+		log.Debug("diagnostics were received", slog.String("op", op), slog.String("external_api", string(snapshot.State)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			views.FromBreaker(snapshot),
+		})
+		return
+	}
+}
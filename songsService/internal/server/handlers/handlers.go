@@ -0,0 +1,63 @@
+// Package handlers contains helpers shared by the HTTP handlers.
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/conditional"
+	"rest/internal/lib/api/response"
+	"rest/internal/storage"
+)
+
+// StorageError renders the error returned by storage with the status matching its kind:
+// not found is 404, conflict is 409, constraint violation is 400, failed precondition is 412 and timeout is 504.
+// Errors of other kinds are rendered as 500 with the fallback message, so internal details never reach clients.
+func StorageError(w http.ResponseWriter, r *http.Request, log *slog.Logger, op string, err error, fallback error) {
+	status, msg := http.StatusInternalServerError, fallback.Error()
+
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		switch {
+		case errors.Is(storageErr.Kind, storage.ErrNotFound):
+			status, msg = http.StatusNotFound, storageErr.Msg
+		case errors.Is(storageErr.Kind, storage.ErrConflict):
+			status, msg = http.StatusConflict, storageErr.Msg
+		case errors.Is(storageErr.Kind, storage.ErrConstraint):
+			status, msg = http.StatusBadRequest, storageErr.Msg
+		case errors.Is(storageErr.Kind, storage.ErrPrecondition):
+			status, msg = http.StatusPreconditionFailed, storageErr.Msg
+		case errors.Is(storageErr.Kind, storage.ErrTimeout):
+			status, msg = http.StatusGatewayTimeout, storageErr.Msg
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		log.Error(fallback.Error(), slog.String("op", op), slog.String("error", err.Error()))
+	} else {
+		log.Debug(msg, slog.String("op", op), slog.String("error", err.Error()))
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, response.Error(msg))
+}
+
+// IfMatch returns the version required by If-Match header of the request, 0 stands for any version.
+// When the header is missing (428) or invalid (400) the response is rendered and ok is false.
+func IfMatch(w http.ResponseWriter, r *http.Request, log *slog.Logger, op string) (version int64, ok bool) {
+	version, err := conditional.IfMatchVersion(r)
+	if err == nil {
+		return version, true
+	}
+
+	log.Debug(err.Error(), slog.String("op", op), slog.String("if_match", r.Header.Get("If-Match")))
+
+	status := http.StatusBadRequest
+	if errors.Is(err, conditional.ErrMissingIfMatch) {
+		status = http.StatusPreconditionRequired
+	}
+	render.Status(r, status)
+	render.JSON(w, r, response.Error(err.Error()))
+	return 0, false
+}
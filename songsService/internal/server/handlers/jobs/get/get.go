@@ -0,0 +1,68 @@
+package get
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/jobs"
+	"rest/pkg/models"
+	"strconv"
+)
+
+type JobGetter interface {
+	GetJob(ctx context.Context, id int64) (*models.JobDTO, error)
+}
+
+type Response struct {
+	response.Response
+	Job views.Job `json:"job"`
+}
+
+// @Summary Get job
+// @Description Retrieve status of the background enrichment of a song added with Prefer: respond-async.
+// @Description Failed attempts are retried with growing delays, the job is failed when it is out of attempts.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /jobs/{id} [get]
+func New(ctx context.Context, log *slog.Logger, jobGetter JobGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/jobs/get.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(jobs.ErrInvalidId.Error()))
+			return
+		}
+
+		job, err := jobGetter.GetJob(ctx, id)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, jobs.ErrGetJob)
+			return
+		}
+
+		log.Debug("job was received", slog.String("op", op), slog.Int64("id", id))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			views.FromJob(job),
+		})
+		return
+	}
+}
@@ -0,0 +1,8 @@
+package jobs
+
+import "errors"
+
+var (
+	ErrGetJob    = errors.New("error getting job")
+	ErrInvalidId = errors.New("invalid job id")
+)
@@ -3,26 +3,29 @@ package add
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
-	"net/url"
+	"rest/internal/clients/info"
 	"rest/internal/lib/api/response"
+	"rest/internal/lib/breaker"
+	"rest/internal/server/handlers"
 	"rest/internal/server/handlers/songs"
+	"rest/internal/storage"
 	"rest/pkg/models"
-	"time"
+	"strconv"
+	"strings"
 )
 
-const ExternalAPIURL = "http://127.0.0.1:8080"
-
 type SongSaver interface {
 	AddSong(ctx context.Context, songDTO *models.SongDTO) (int64, error)
+	AddPendingSong(ctx context.Context, songDTO *models.SongDTO) (int64, int64, error)
+	FindSong(ctx context.Context, groupName string, songName string) (int64, error)
 }
 
-type CacheSaver interface {
-	Set(ctx context.Context, songDTO *models.SongDTO) error
+type InfoGetter interface {
+	GetInfo(ctx context.Context, groupName string, songName string) (*models.SongDTO, error)
 }
 
 type Request struct {
@@ -32,27 +35,34 @@ type Request struct {
 
 type Response struct {
 	response.Response
-	Id int64 `json:"id"`
-}
-
-type ExternalAPIResponse struct {
-	Text        string    `json:"text"`
-	ReleaseDate time.Time `json:"release_date"`
-	Link        string    `json:"link"`
-	Error       string    `json:"error,omitempty"`
+	Id    int64 `json:"id"`
+	JobId int64 `json:"job_id,omitempty"`
 }
 
 // @Summary Add a new song
-// @Description Add a new song by group name and song name.
+// @Description Add a new song by group name and song name. Names are unique per artist case-insensitively,
+// @Description adding an existing song fails with 409 and the id of the existing song.
+// @Description Requests with Idempotency-Key header are safe to retry, retries get the response to the first request.
+// @Description With Prefer: respond-async the song is saved without details and 202 is returned at once,
+// @Description details are requested from the external API in background, progress is available at /jobs/{job_id}.
+// @Description The same happens when the external API fails and the service runs in degraded mode.
 // @Tags songs
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Client-generated key of the request"
+// @Param Prefer header string false "respond-async to enrich the song in background"
 // @Param request body Request true "Song details"
 // @Success 201 {object} Response "Successful add new song"
+// @Success 202 {object} Response "Song was saved and will be enriched in background"
 // @Failure 400 {object} response.Response "Bad request error response"
+// @Failure 409 {object} Response "Song already exists or request with the key is in progress"
+// @Failure 422 {object} response.Response "Idempotency-Key was used with another request or the external API rejected the song"
 // @Failure 500 {object} response.Response "Internal server error response"
+// @Failure 502 {object} response.Response "External API failed"
+// @Failure 503 {object} response.Response "External API is failing, requests to it are suspended"
+// @Failure 504 {object} response.Response "External API did not respond in time"
 // @Router /songs [post]
-func New(ctx context.Context, log *slog.Logger, songSaver SongSaver, cache CacheSaver) http.HandlerFunc {
+func New(ctx context.Context, log *slog.Logger, songSaver SongSaver, infoGetter InfoGetter, degraded bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "server/handlers/songs/add.New"
 
@@ -92,74 +102,133 @@ func New(ctx context.Context, log *slog.Logger, songSaver SongSaver, cache Cache
 		}
 
 		groupName, songName := req.GroupName, req.SongName
-		respFromExternalAPI, err := http.Get(
-			fmt.Sprintf("%s/info?group_name=%s&song_name=%s",
-				ExternalAPIURL,
-				url.QueryEscape(groupName),
-				url.QueryEscape(songName)),
-		)
 
-		if err != nil {
-			log.Error("failed to send external API request",
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to send external API request"))
+		// the song may already exist, there is no need to ask the external API about it then
+		existingId, err := songSaver.FindSong(ctx, groupName, songName)
+		if err == nil {
+			renderExists(w, r, existingId)
+			return
+		}
+		if !errors.Is(err, storage.ErrSongNotFound) {
+			handlers.StorageError(w, r, log, op, err, songs.ErrAddSong)
 			return
 		}
-		defer func() {
-			if err = respFromExternalAPI.Body.Close(); err != nil {
-				log.Error("failed to close response body",
-					slog.String("op", op),
-					slog.String("error", err.Error()),
-				)
-			}
-		}()
 
-		var externalAPIResponse ExternalAPIResponse
-		if err = render.DecodeJSON(respFromExternalAPI.Body, &externalAPIResponse); err != nil {
-			log.Error("failed to parse external API request",
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to parse external API request, try again later"))
+		if respondAsync(r) {
+			addPending(ctx, w, r, log, op, songSaver, groupName, songName)
 			return
 		}
 
-		if respFromExternalAPI.StatusCode != http.StatusOK {
-			log.Error("error in external API response",
+		// the external request is bound to the client request, there is no one to respond to when it is gone
+		details, err := infoGetter.GetInfo(r.Context(), groupName, songName)
+		if err != nil && degraded && !errors.Is(err, info.ErrClient) && r.Context().Err() == nil {
+			log.Warn("external API failed, song is saved for later enrichment",
 				slog.String("op", op),
-				slog.Int("received status_code", respFromExternalAPI.StatusCode),
-				slog.String("error", externalAPIResponse.Error),
+				slog.String("error", err.Error()),
 			)
-			render.Status(r, http.StatusBadGateway)
-			render.JSON(w, r, response.Error(externalAPIResponse.Error))
+			addPending(ctx, w, r, log, op, songSaver, groupName, songName)
+			return
+		}
+		if err != nil {
+			externalAPIError(w, r, log, op, err)
 			return
 		}
 
 		songDTO := models.SongDTO{
 			GroupName:   groupName,
 			SongName:    songName,
-			ReleaseDate: externalAPIResponse.ReleaseDate,
-			Text:        externalAPIResponse.Text,
-			Link:        externalAPIResponse.Link,
+			ReleaseDate: details.ReleaseDate,
+			Text:        details.Text,
+			Link:        details.Link,
 		}
 		id, err := songSaver.AddSong(ctx, &songDTO)
+		if errors.Is(err, storage.ErrSongExists) {
+			// the same song was added concurrently
+			if existingId, err = songSaver.FindSong(ctx, groupName, songName); err == nil {
+				renderExists(w, r, existingId)
+				return
+			}
+		}
 		if err != nil {
-			log.Error("failed to add get", slog.String("op", op), slog.String("error", err.Error()))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to add new get"))
+			handlers.StorageError(w, r, log, op, err, songs.ErrAddSong)
 			return
 		}
 
-		if err = cache.Set(ctx, &songDTO); err != nil {
-			log.Error("failed to set cache", slog.String("op", op), slog.String("error", err.Error()))
-		}
-
 		render.Status(r, http.StatusCreated)
-		render.JSON(w, r, Response{response.OK(), id})
+		render.JSON(w, r, Response{Response: response.OK(), Id: id})
+		return
+	}
+}
+
+// addPending saves the song without details and responds with the id of the job enriching it.
+func addPending(ctx context.Context, w http.ResponseWriter, r *http.Request, log *slog.Logger, op string,
+	songSaver SongSaver, groupName string, songName string) {
+
+	songId, jobId, err := songSaver.AddPendingSong(ctx, &models.SongDTO{GroupName: groupName, SongName: songName})
+	if errors.Is(err, storage.ErrSongExists) {
+		var existingId int64
+		if existingId, err = songSaver.FindSong(ctx, groupName, songName); err == nil {
+			renderExists(w, r, existingId)
+			return
+		}
+	}
+	if err != nil {
+		handlers.StorageError(w, r, log, op, err, songs.ErrAddSong)
 		return
 	}
+
+	log.Debug("song was added for enrichment", slog.String("op", op),
+		slog.Int64("id", songId), slog.Int64("job_id", jobId))
+
+	w.Header().Set("Location", "/jobs/"+strconv.FormatInt(jobId, 10))
+	if respondAsync(r) {
+		w.Header().Set("Preference-Applied", "respond-async")
+	}
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, Response{response.OK(), songId, jobId})
+}
+
+func renderExists(w http.ResponseWriter, r *http.Request, id int64) {
+	render.Status(r, http.StatusConflict)
+	render.JSON(w, r, Response{Response: response.Error(storage.ErrSongExists.Error()), Id: id})
+}
+
+// externalAPIError renders the failure to get details of the song, the song rejected by the external API
+// is reported as unprocessable with the reason given by the API.
+func externalAPIError(w http.ResponseWriter, r *http.Request, log *slog.Logger, op string, err error) {
+	log.Error("failed to get song info from external API",
+		slog.String("op", op),
+		slog.String("error", err.Error()),
+	)
+
+	var infoErr *info.Error
+	switch {
+	case errors.Is(err, breaker.ErrOpen):
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, response.Error(songs.ErrExternalAPIUnavailable.Error()))
+	case errors.Is(err, context.DeadlineExceeded):
+		render.Status(r, http.StatusGatewayTimeout)
+		render.JSON(w, r, response.Error(songs.ErrExternalAPITimeout.Error()))
+	case errors.Is(err, info.ErrClient) && errors.As(err, &infoErr) && infoErr.Msg != "":
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, response.Error(infoErr.Msg))
+	case errors.Is(err, info.ErrClient):
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, response.Error(info.ErrClient.Error()))
+	default:
+		render.Status(r, http.StatusBadGateway)
+		render.JSON(w, r, response.Error(songs.ErrExternalAPI.Error()))
+	}
+}
+
+// respondAsync reports whether the client prefers the request to be processed asynchronously, see RFC 7240.
+func respondAsync(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(preference), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
 }
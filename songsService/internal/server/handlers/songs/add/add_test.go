@@ -0,0 +1,111 @@
+package add
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"rest/internal/clients/info"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/breaker"
+	"rest/internal/server/handlers/songs"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"strings"
+	"testing"
+)
+
+// stubSaver saves nothing, no song exists.
+type stubSaver struct{}
+
+func (stubSaver) AddSong(context.Context, *models.SongDTO) (int64, error) {
+	return 1, nil
+}
+
+func (stubSaver) AddPendingSong(context.Context, *models.SongDTO) (int64, int64, error) {
+	return 1, 1, nil
+}
+
+func (stubSaver) FindSong(context.Context, string, string) (int64, error) {
+	return 0, storage.ErrSongNotFound
+}
+
+// stubInfo fails with err.
+type stubInfo struct {
+	err error
+}
+
+func (i stubInfo) GetInfo(context.Context, string, string) (*models.SongDTO, error) {
+	return nil, i.err
+}
+
+func TestExternalAPIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		msg    string
+	}{
+		{
+			name:   "breaker is open",
+			err:    &info.Error{Kind: info.ErrUnavailable, Err: breaker.ErrOpen},
+			status: http.StatusServiceUnavailable,
+			msg:    songs.ErrExternalAPIUnavailable.Error(),
+		},
+		{
+			name:   "timeout",
+			err:    &info.Error{Kind: info.ErrUnavailable, Err: context.DeadlineExceeded},
+			status: http.StatusGatewayTimeout,
+			msg:    songs.ErrExternalAPITimeout.Error(),
+		},
+		{
+			name:   "rejected with reason",
+			err:    &info.Error{Kind: info.ErrClient, StatusCode: http.StatusBadRequest, Msg: "unknown song"},
+			status: http.StatusUnprocessableEntity,
+			msg:    "unknown song",
+		},
+		{
+			name:   "rejected without reason",
+			err:    &info.Error{Kind: info.ErrClient, StatusCode: http.StatusNotFound},
+			status: http.StatusUnprocessableEntity,
+			msg:    info.ErrClient.Error(),
+		},
+		{
+			name:   "server error",
+			err:    &info.Error{Kind: info.ErrServer, StatusCode: http.StatusInternalServerError},
+			status: http.StatusBadGateway,
+			msg:    songs.ErrExternalAPI.Error(),
+		},
+		{
+			name:   "malformed response",
+			err:    &info.Error{Kind: info.ErrDecode},
+			status: http.StatusBadGateway,
+			msg:    songs.ErrExternalAPI.Error(),
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := New(context.Background(), log, stubSaver{}, stubInfo{tt.err}, false)
+
+			req := httptest.NewRequest(http.MethodPost, "/songs",
+				strings.NewReader(`{"group_name":"Muse","song_name":"Hysteria"}`))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var resp response.Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error != tt.msg {
+				t.Errorf("error = %q, want %q", resp.Error, tt.msg)
+			}
+		})
+	}
+}
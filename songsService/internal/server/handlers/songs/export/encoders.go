@@ -0,0 +1,129 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"rest/internal/lib/api/views"
+	tp "rest/internal/lib/timeParser"
+	"rest/pkg/models"
+	"strconv"
+	"time"
+)
+
+// encoder writes songs in one of the export formats.
+type encoder interface {
+	Begin() error
+	Song(song *views.Song) error
+	End() error
+}
+
+type format struct {
+	contentType string
+	extension   string
+	newEncoder  func(w io.Writer, fields []models.Column) encoder
+}
+
+var formats = map[string]format{
+	"csv": {"text/csv; charset=utf-8", "csv", func(w io.Writer, fields []models.Column) encoder {
+		return &csvEncoder{writer: csv.NewWriter(w), fields: fields}
+	}},
+	"ndjson": {"application/x-ndjson", "ndjson", func(w io.Writer, fields []models.Column) encoder {
+		return &ndjsonEncoder{encoder: json.NewEncoder(w), fields: fields}
+	}},
+	"json": {"application/json", "json", func(w io.Writer, fields []models.Column) encoder {
+		return &jsonEncoder{writer: w, fields: fields}
+	}},
+}
+
+// csvEncoder writes a header with field names and a record per song.
+type csvEncoder struct {
+	writer *csv.Writer
+	fields []models.Column
+}
+
+func (e *csvEncoder) Begin() error {
+	header := make([]string, 0, len(e.fields))
+	for _, field := range e.fields {
+		header = append(header, models.FieldName(field))
+	}
+	return e.writer.Write(header)
+}
+
+func (e *csvEncoder) Song(song *views.Song) error {
+	record := make([]string, 0, len(e.fields))
+	for _, field := range e.fields {
+		var value string
+		switch field {
+		case models.ColumnId:
+			value = strconv.FormatInt(song.Id, 10)
+		case models.ColumnGroupName:
+			value = song.GroupName
+		case models.ColumnSongName:
+			value = song.SongName
+		case models.ColumnReleaseDate:
+			if date := time.Time(song.ReleaseDate); !date.IsZero() {
+				value = tp.FormatDate(date)
+			}
+		case models.ColumnText:
+			value = song.Text
+		case models.ColumnLink:
+			value = song.Link
+		}
+		record = append(record, value)
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvEncoder) End() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// ndjsonEncoder writes a JSON object per line.
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+	fields  []models.Column
+}
+
+func (e *ndjsonEncoder) Begin() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) Song(song *views.Song) error {
+	return e.encoder.Encode(song.Fields(e.fields))
+}
+
+func (e *ndjsonEncoder) End() error {
+	return nil
+}
+
+// jsonEncoder writes a JSON array of songs element by element.
+type jsonEncoder struct {
+	writer io.Writer
+	fields []models.Column
+	count  int
+}
+
+func (e *jsonEncoder) Begin() error {
+	_, err := io.WriteString(e.writer, "[")
+	return err
+}
+
+func (e *jsonEncoder) Song(song *views.Song) error {
+	data, err := json.Marshal(song.Fields(e.fields))
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		data = append([]byte(",\n"), data...)
+	}
+	e.count++
+	_, err = e.writer.Write(data)
+	return err
+}
+
+func (e *jsonEncoder) End() error {
+	_, err := io.WriteString(e.writer, "]\n")
+	return err
+}
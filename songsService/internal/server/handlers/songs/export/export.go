@@ -0,0 +1,192 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/songs"
+	"rest/pkg/models"
+	"strings"
+	"time"
+)
+
+// flushEvery is the number of songs written between flushes of the response.
+const flushEvery = 1000
+
+type SongsExporter interface {
+	ExportSongs(ctx context.Context, where *models.WhereBuilder, orders []models.Order, fields []models.Column,
+		fn func(song *models.SongDTO) error) error
+}
+
+// @Summary Export songs
+// @Description Stream all songs matching the filters as a file, rows are read from the database as they are written.
+// @Tags songs
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson, json) default(json)
+// @Param fields query string false "Comma-separated fields to export: id, group_name, song_name, release_date, text, link" default(id,group_name,song_name,release_date,text,link)
+// @Param sort query string false "Comma-separated sort keys: id, group_name, song_name, release_date; prefix - for descending order" default(id)
+// @Param id query int false "Song ID"
+// @Param release_date query string false "Release date"
+// @Param group_name query string false "Group name"
+// @Param group_name_prefix query string false "Case-insensitive group name prefix"
+// @Param group_name_contains query string false "Case-insensitive group name substring"
+// @Param group_name_similar query string false "Group name similar by trigrams, tolerates typos"
+// @Param song_name query string false "Song name"
+// @Param song_name_prefix query string false "Case-insensitive song name prefix"
+// @Param song_name_contains query string false "Case-insensitive song name substring"
+// @Param song_name_similar query string false "Song name similar by trigrams, tolerates typos"
+// @Param from query string false "Songs released on this date or later, YYYY-MM-DD"
+// @Param to query string false "Songs released on this date or earlier, YYYY-MM-DD"
+// @Param ids query []int false "Comma-separated songs IDs" collectionFormat(csv)
+// @Param song_text query string false "Song text"
+// @Param link query string false "Song link"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /songs/export [get]
+func New(ctx context.Context, log *slog.Logger, songsExporter SongsExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/songs/export.New"
+
+		err := r.ParseForm()
+		if err != nil {
+			log.Error("unable to parse form", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+			return
+		}
+
+		name := strings.ToLower(r.FormValue("format"))
+		if name == "" {
+			name = "json"
+		}
+		exportFormat, ok := formats[name]
+		if !ok {
+			log.Error("unknown export format", slog.String("op", op), slog.String("format", name))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrInvalidFormat.Error()))
+			return
+		}
+
+		orders, err := models.ParseSort(r.FormValue("sort"))
+		if err != nil {
+			log.Error("unable to parse sort",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(models.ErrInvalidSort.Error()))
+			return
+		}
+
+		// unlike list calls, export contains lyrics unless the fields are given explicitly
+		fields := models.AllFields
+		if r.FormValue("fields") != "" {
+			fields, err = models.ParseFields(r.FormValue("fields"))
+			if err != nil {
+				log.Error("unable to parse fields",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(models.ErrInvalidFields.Error()))
+				return
+			}
+		}
+
+		where, err := songs.ParseFilter(r)
+		if err != nil {
+			log.Error("unable to parse filter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+			return
+		}
+
+		// export may take longer than the server write timeout
+		controller := http.NewResponseController(w)
+		if err = controller.SetWriteDeadline(time.Time{}); err != nil {
+			log.Warn("unable to clear write deadline", slog.String("op", op), slog.String("error", err.Error()))
+		}
+
+		buffered := bufio.NewWriter(w)
+		encoder := exportFormat.newEncoder(buffered, fields)
+		started := false
+		count := 0
+
+		// headers are sent with the first song, so storage errors before it still get a proper status
+		begin := func() error {
+			started = true
+			w.Header().Set("Content-Type", exportFormat.contentType)
+			w.Header().Set("Content-Disposition", `attachment; filename="songs.`+exportFormat.extension+`"`)
+			w.WriteHeader(http.StatusOK)
+			return encoder.Begin()
+		}
+
+		// the query is bound to the request, so it is cancelled as soon as the client goes away
+		err = songsExporter.ExportSongs(r.Context(), where, orders, fields, func(song *models.SongDTO) error {
+			if !started {
+				if err := begin(); err != nil {
+					return err
+				}
+			}
+
+			view := views.FromSong(song)
+			if err := encoder.Song(&view); err != nil {
+				return err
+			}
+
+			count++
+			if count%flushEvery == 0 {
+				if err := buffered.Flush(); err != nil {
+					return err
+				}
+				return controller.Flush()
+			}
+			return nil
+		})
+		if err != nil && !started {
+			handlers.StorageError(w, r, log, op, err, songs.ErrExportSongs)
+			return
+		}
+		if err != nil {
+			// the status is already sent, the client sees a truncated file
+			log.Error("export was interrupted",
+				slog.String("op", op),
+				slog.Int("count", count),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		if !started {
+			err = begin()
+		}
+		if err == nil {
+			err = encoder.End()
+		}
+		if err == nil {
+			err = buffered.Flush()
+		}
+		if err != nil {
+			log.Error("unable to finish export",
+				slog.String("op", op),
+				slog.Int("count", count),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		log.Debug("songs were exported", slog.String("op", op), slog.Int("count", count))
+		return
+	}
+}
@@ -0,0 +1,79 @@
+package songs
+
+import (
+	"fmt"
+	"net/http"
+	tp "rest/internal/lib/timeParser"
+	"rest/pkg/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseFilter builds the where clause from the filter parameters of the parsed form of the request,
+// errors wrap ErrParseRequest.
+func ParseFilter(r *http.Request) (*models.WhereBuilder, error) {
+	songDTO := &models.SongDTO{}
+
+	if r.FormValue("id") != "" {
+		id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: id: %w", ErrParseRequest, err)
+		}
+		songDTO.Id = id
+	}
+
+	if r.FormValue("release_date") != "" {
+		releaseDate, err := tp.ParseToDate(r.FormValue("release_date"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: release_date: %w", ErrParseRequest, err)
+		}
+		songDTO.ReleaseDate = releaseDate
+	}
+
+	var from, to time.Time
+	for param, date := range map[string]*time.Time{"from": &from, "to": &to} {
+		if r.FormValue(param) == "" {
+			continue
+		}
+		var err error
+		*date, err = tp.ParseToDate(r.FormValue(param))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrParseRequest, param, err)
+		}
+	}
+
+	var ids []int64
+	for _, value := range r.Form["ids"] {
+		for _, rawId := range strings.Split(value, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(rawId), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: ids: %w", ErrParseRequest, err)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	songDTO.GroupName = r.FormValue("group_name")
+	songDTO.SongName = r.FormValue("song_name")
+	songDTO.Text = r.FormValue("song_text")
+	songDTO.Link = r.FormValue("link")
+
+	return models.Where(
+		models.Eq(models.ColumnId, songDTO.Id),
+		models.Eq(models.ColumnReleaseDate, songDTO.ReleaseDate),
+		models.Eq(models.ColumnGroupName, songDTO.GroupName),
+		models.Eq(models.ColumnSongName, songDTO.SongName),
+		models.Eq(models.ColumnLink, songDTO.Link),
+		models.Eq(models.ColumnText, songDTO.Text),
+		models.In(models.ColumnId, ids),
+		models.Prefix(models.ColumnGroupName, r.FormValue("group_name_prefix")),
+		models.Contains(models.ColumnGroupName, r.FormValue("group_name_contains")),
+		models.Similar(models.ColumnGroupName, r.FormValue("group_name_similar")),
+		models.Prefix(models.ColumnSongName, r.FormValue("song_name_prefix")),
+		models.Contains(models.ColumnSongName, r.FormValue("song_name_contains")),
+		models.Similar(models.ColumnSongName, r.FormValue("song_name_similar")),
+		models.Gte(models.ColumnReleaseDate, from),
+		models.Lte(models.ColumnReleaseDate, to),
+	), nil
+}
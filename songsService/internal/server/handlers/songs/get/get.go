@@ -6,20 +6,23 @@ import (
 	"log/slog"
 	"net/http"
 	"rest/internal/lib/api/response"
-	tp "rest/internal/lib/timeParser"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
 	"rest/internal/server/handlers/songs"
 	"rest/pkg/models"
 	"strconv"
-	"time"
 )
 
 type SongsGetter interface {
-	GetSongs(ctx context.Context, songDTO *models.SongDTO, page int, limit int, opts ...models.OptionFunc) ([]models.SongDTO, error)
+	GetSongs(ctx context.Context, where *models.WhereBuilder, orders []models.Order, fields []models.Column,
+		page models.Page) ([]models.SongDTO, bool, error)
+	CountSongs(ctx context.Context, where *models.WhereBuilder) (int, error)
 }
 
 type Response struct {
 	response.Response
-	Songs []models.SongDTO `json:"songs"`
+	Songs      []map[string]any    `json:"songs"`
+	Pagination response.Pagination `json:"pagination"`
 }
 
 // @Summary Get songs
@@ -28,11 +31,23 @@ type Response struct {
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param limit query int false "Number of results per page" default(10)
+// @Param limit query int false "Number of results per page" default(10) maximum(100)
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of the previous response, replaces page"
+// @Param fields query string false "Comma-separated fields to return: id, group_name, song_name, release_date, text, link" default(id,group_name,song_name,release_date,link)
+// @Param sort query string false "Comma-separated sort keys: id, group_name, song_name, release_date; prefix - for descending order" default(id)
 // @Param id query int false "Song ID"
 // @Param release_date query string false "Release date"
 // @Param group_name query string false "Group name"
+// @Param group_name_prefix query string false "Case-insensitive group name prefix"
+// @Param group_name_contains query string false "Case-insensitive group name substring"
+// @Param group_name_similar query string false "Group name similar by trigrams, tolerates typos"
 // @Param song_name query string false "Song name"
+// @Param song_name_prefix query string false "Case-insensitive song name prefix"
+// @Param song_name_contains query string false "Case-insensitive song name substring"
+// @Param song_name_similar query string false "Song name similar by trigrams, tolerates typos"
+// @Param from query string false "Songs released on this date or later, YYYY-MM-DD"
+// @Param to query string false "Songs released on this date or earlier, YYYY-MM-DD"
+// @Param ids query []int false "Comma-separated songs IDs" collectionFormat(csv)
 // @Param song_text query string false "Song text"
 // @Param link query string false "Song link"
 // @Success 200 {object} Response
@@ -45,8 +60,6 @@ func New(ctx context.Context, log *slog.Logger, songsGetter SongsGetter) http.Ha
 
 		page, limit := 1, 10
 
-		songDTO := &models.SongDTO{}
-
 		err := r.ParseForm()
 		if err != nil {
 			log.Error("unable to parse form", slog.String("op", op), slog.String("error", err.Error()))
@@ -80,67 +93,140 @@ func New(ctx context.Context, log *slog.Logger, songsGetter SongsGetter) http.Ha
 			}
 		}
 
-		if r.FormValue("id") != "" {
-			var id int64
-			id, err = strconv.ParseInt(r.FormValue("id"), 10, 64)
-			if err != nil {
-				log.Error("unable to parse id",
-					slog.String("op", op),
-					slog.String("error", err.Error()),
-				)
-				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
-				return
-			}
-			songDTO.Id = id
+		orders, err := models.ParseSort(r.FormValue("sort"))
+		if err != nil {
+			log.Error("unable to parse sort",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(models.ErrInvalidSort.Error()))
+			return
 		}
 
-		if r.FormValue("release_date") != "" {
-			var releaseDate time.Time
-			releaseDate, err = tp.ParseToDate(r.FormValue("release_date"))
+		fields, err := models.ParseFields(r.FormValue("fields"))
+		if err != nil {
+			log.Error("unable to parse fields",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(models.ErrInvalidFields.Error()))
+			return
+		}
+
+		var cursor *models.Cursor
+		if r.FormValue("cursor") != "" {
+			cursor, err = models.DecodeCursor(r.FormValue("cursor"))
+			if err == nil && !cursor.Matches(orders) {
+				err = models.ErrInvalidCursor
+			}
 			if err != nil {
-				log.Error("unable to parse release_date",
+				log.Error("unable to parse cursor",
 					slog.String("op", op),
 					slog.String("error", err.Error()),
 				)
 				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+				render.JSON(w, r, response.Error(models.ErrInvalidCursor.Error()))
 				return
 			}
-			songDTO.ReleaseDate = releaseDate
 		}
 
-		songDTO.GroupName = r.FormValue("group_name")
-		songDTO.SongName = r.FormValue("song_name")
-		songDTO.Text = r.FormValue("song_text")
-		songDTO.Link = r.FormValue("link")
-
-		songsData, err := songsGetter.GetSongs(
-			ctx,
-			songDTO,
-			page,
-			limit,
-			models.WithId(),
-			models.WithReleaseDate(),
-			models.WithGroupName(),
-			models.WithSongName(),
-			models.WithLink(),
-			models.WithSongText(),
-		)
+		if err = models.ValidatePage(page, limit); err != nil {
+			log.Error("invalid page", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		where, err := songs.ParseFilter(r)
+		if err != nil {
+			log.Error("unable to parse filter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+			return
+		}
+
+		songsData, hasMore, err := songsGetter.GetSongs(ctx, where, orders, fields,
+			models.Page{Number: page, Limit: limit, Cursor: cursor})
 		if err != nil {
-			log.Error("unable to get songs", slog.String("op", op), slog.String("error", err.Error()))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(songs.ErrGetSongs.Error()))
+			handlers.StorageError(w, r, log, op, err, songs.ErrGetSongs)
 			return
 		}
 
-		log.Debug("songs were received", slog.String("op", op))
+		total, err := songsGetter.CountSongs(ctx, where)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, songs.ErrGetSongs)
+			return
+		}
+
+		log.Debug("songs were received", slog.String("op", op), slog.Int("total", total))
 
 		render.Status(r, http.StatusOK)
 		render.JSON(w, r, Response{
 			response.OK(),
-			songsData,
+			project(songsData, fields),
+			paginate(r, songsData, orders, hasMore, total, page, limit, cursor),
 		})
 		return
 	}
 }
+
+func paginate(r *http.Request, songsData []models.SongDTO, orders []models.Order, hasMore bool, total int,
+	page int, limit int, cursor *models.Cursor) response.Pagination {
+
+	pagination := response.NewPagination(total, page, limit)
+	if cursor != nil {
+		pagination.Page = 0
+	}
+
+	if cursor == nil {
+		if hasMore {
+			pagination.Next = response.Link(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if page > 1 {
+			pagination.Prev = response.Link(r, map[string]string{"page": strconv.Itoa(max(1, min(page-1, pagination.Pages)))})
+		}
+	}
+	if len(songsData) == 0 {
+		return pagination
+	}
+
+	// hasMore is reported in the direction of the cursor, the opposite direction is where the client came from
+	hasNext, hasPrev := hasMore, page > 1
+	if cursor != nil {
+		hasNext, hasPrev = true, true
+		if cursor.Backward {
+			hasPrev = hasMore
+		} else {
+			hasNext = hasMore
+		}
+	}
+
+	if hasNext {
+		pagination.NextCursor = models.NewCursor(&songsData[len(songsData)-1], orders, false).Encode()
+		if cursor != nil {
+			pagination.Next = response.Link(r, map[string]string{"cursor": pagination.NextCursor, "page": ""})
+		}
+	}
+	if hasPrev {
+		pagination.PrevCursor = models.NewCursor(&songsData[0], orders, true).Encode()
+		if cursor != nil {
+			pagination.Prev = response.Link(r, map[string]string{"cursor": pagination.PrevCursor, "page": ""})
+		}
+	}
+
+	return pagination
+}
+
+// project keeps only the requested fields of the songs.
+func project(songsData []models.SongDTO, fields []models.Column) []map[string]any {
+	projected := make([]map[string]any, 0, len(songsData))
+	for _, song := range views.FromSongs(songsData) {
+		projected = append(projected, song.Fields(fields))
+	}
+	return projected
+}
@@ -4,10 +4,24 @@ import "errors"
 
 var (
 	ErrDecodeRequest = errors.New("error decoding request")
+	ErrAddSong       = errors.New("error adding song")
 	ErrUpdateSong    = errors.New("error updating song data")
 	ErrDeleteSong    = errors.New("error deleting song data")
 	ErrParseRequest  = errors.New("error parsing request")
 	ErrGetSongs      = errors.New("error getting songs")
+	ErrGetSong       = errors.New("error getting song")
 	ErrGetVerses     = errors.New("error getting verses")
 	ErrMissingId     = errors.New("missing song id")
+	ErrInvalidId     = errors.New("invalid song id")
+	ErrMissingQuery  = errors.New("missing search query")
+	ErrSearchSongs   = errors.New("error searching songs")
+	ErrImportSongs   = errors.New("error importing songs")
+	ErrExportSongs   = errors.New("error exporting songs")
+	ErrInvalidFormat = errors.New("invalid format, expected csv, ndjson or json")
+	ErrExternalAPI   = errors.New("failed to get song info from external API, try again later")
+
+	ErrExternalAPITimeout     = errors.New("external API did not respond in time, try again later")
+	ErrExternalAPIUnavailable = errors.New("external API is unavailable, try again later")
+
+	ErrUnsupportedMediaType = errors.New("unsupported content type, expected application/merge-patch+json")
 )
@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"io"
+	"log/slog"
+	"net/http"
+	"rest/internal/importer"
+	"rest/internal/lib/api/response"
+	"rest/internal/server/handlers/songs"
+	"time"
+)
+
+// maxFileSize limits the size of the imported file, larger catalogs are imported with cmd/importer.
+const maxFileSize = 64 << 20
+
+type SongsImporter interface {
+	Import(ctx context.Context, r io.Reader, format importer.Format) (*importer.Report, error)
+}
+
+type Response struct {
+	response.Response
+	importer.Report
+}
+
+// @Summary Import songs
+// @Description Import songs from CSV file with a header or from NDJSON file with one song per line.
+// @Description Fields are group_name, song_name, release_date, text and link, missing text, link and release date
+// @Description are requested from the external API. Every row gets its own result, existing songs are skipped.
+// @Tags songs
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param format query string false "File format, taken from Content-Type when omitted" Enums(csv, ndjson)
+// @Param file body string true "CSV or NDJSON file"
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /songs/import [post]
+func New(ctx context.Context, log *slog.Logger, songsImporter SongsImporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/songs/imports.New"
+
+		formatName := r.URL.Query().Get("format")
+		if formatName == "" {
+			formatName = r.Header.Get("Content-Type")
+		}
+		format, err := importer.ParseFormat(formatName)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		// enrichment of a large file outlives the server timeouts
+		rc := http.NewResponseController(w)
+		if err = rc.SetReadDeadline(time.Time{}); err == nil {
+			err = rc.SetWriteDeadline(time.Time{})
+		}
+		if err != nil {
+			log.Debug("unable to reset deadlines", slog.String("op", op), slog.String("error", err.Error()))
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxFileSize)
+		defer func() {
+			if err := body.Close(); err != nil {
+				log.Error("Failed to close request body: %v",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+
+		report, err := songsImporter.Import(ctx, body, format)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &maxBytesErr):
+				render.Status(r, http.StatusRequestEntityTooLarge)
+				render.JSON(w, r, response.Error("file is too large, use the importer command for large files"))
+			case errors.Is(err, importer.ErrInvalidHeader):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(errors.Unwrap(err).Error()))
+			default:
+				log.Error(songs.ErrImportSongs.Error(), slog.String("op", op), slog.String("error", err.Error()))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error(songs.ErrImportSongs.Error()))
+			}
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			*report,
+		})
+		return
+	}
+}
@@ -0,0 +1,192 @@
+package patch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"mime"
+	"net/http"
+	"rest/internal/lib/api/conditional"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	tp "rest/internal/lib/timeParser"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/songs"
+	"rest/pkg/models"
+	"strconv"
+	"strings"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents (RFC 7396).
+const MergePatchContentType = "application/merge-patch+json"
+
+type SongUpdater interface {
+	UpdateSong(ctx context.Context, id int64, version int64, set *models.SetBuilder) (*models.SongDTO, error)
+}
+
+// Request describes the merge patch document: absent fields are kept, null clears the field.
+// Group and song names can not be cleared, a song with cleared release date inherits it from its album.
+type Request struct {
+	GroupName   string  `json:"group_name,omitempty"`
+	SongName    string  `json:"song_name,omitempty"`
+	Text        *string `json:"text,omitempty" extensions:"x-nullable"`
+	ReleaseDate *string `json:"release_date,omitempty" format:"date" example:"2006-07-16" extensions:"x-nullable"`
+	Link        *string `json:"link,omitempty" extensions:"x-nullable"`
+}
+
+type Response struct {
+	response.Response
+	Song views.Song `json:"song"`
+}
+
+// @Summary Patch a song
+// @Description Partially update a song with a JSON Merge Patch document (RFC 7396).
+// @Description Absent fields are kept, null clears text and link and makes the song inherit release date from its album.
+// @Tags songs
+// @Accept application/merge-patch+json,json
+// @Produce json
+// @Param id path int true "Song ID"
+// @Param If-Match header string true "ETag of the current song or *"
+// @Param request body Request true "Merge patch document"
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /songs/{id} [patch]
+func New(ctx context.Context, log *slog.Logger, songUpdater SongUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/songs/patch.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrInvalidId.Error()))
+			return
+		}
+
+		version, ok := handlers.IfMatch(w, r, log, op)
+		if !ok {
+			return
+		}
+
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || (mediaType != MergePatchContentType && mediaType != "application/json") {
+				render.Status(r, http.StatusUnsupportedMediaType)
+				render.JSON(w, r, response.Error(songs.ErrUnsupportedMediaType.Error()))
+				return
+			}
+		}
+
+		var doc map[string]json.RawMessage
+		if err = render.DecodeJSON(r.Body, &doc); err != nil {
+			log.Error(songs.ErrDecodeRequest.Error(),
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrDecodeRequest.Error()))
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.Error("Failed to close request body: %v",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+
+		log.Debug("request was successfully decoded", slog.String("op", op), slog.Any("request", doc))
+
+		set, err := buildSet(doc)
+		if err != nil {
+			log.Error("failed to validate request", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		song, err := songUpdater.UpdateSong(ctx, id, version, set)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, songs.ErrUpdateSong)
+			return
+		}
+
+		view := views.FromSong(song)
+		etag := conditional.VersionETag(song.Version)
+		conditional.SetValidators(w, etag, song.UpdatedAt)
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			view,
+		})
+		return
+	}
+}
+
+// buildSet converts the merge patch document into assignments, the document is flat
+// since every patchable field of a song is a string.
+func buildSet(doc map[string]json.RawMessage) (*models.SetBuilder, error) {
+	set := models.Set()
+	for field, raw := range doc {
+		column, err := models.ParseField(field)
+		if err != nil || column == models.ColumnId {
+			return nil, fmt.Errorf("field %s can not be patched", field)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			switch column {
+			case models.ColumnGroupName, models.ColumnSongName:
+				return nil, fmt.Errorf("field %s can not be null", field)
+			case models.ColumnReleaseDate:
+				set.Value(column, nil)
+			default:
+				set.Value(column, "")
+			}
+			continue
+		}
+
+		var value string
+		if err = json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("field %s must be a string", field)
+		}
+
+		switch column {
+		case models.ColumnGroupName, models.ColumnSongName:
+			if strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("field %s can not be empty", field)
+			}
+			set.Value(column, value)
+		case models.ColumnReleaseDate:
+			date, err := tp.ParseToDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("field %s is not a valid date", field)
+			}
+			set.Value(column, date)
+		case models.ColumnLink:
+			if value != "" && validator.New().Var(value, "url") != nil {
+				return nil, fmt.Errorf("field %s is not a valid URL", field)
+			}
+			set.Value(column, value)
+		default:
+			set.Value(column, value)
+		}
+	}
+
+	return set, nil
+}
@@ -0,0 +1,137 @@
+package replace
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/conditional"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/songs"
+	"rest/pkg/models"
+	"strconv"
+	"time"
+)
+
+type SongUpdater interface {
+	UpdateSong(ctx context.Context, id int64, version int64, set *models.SetBuilder) (*models.SongDTO, error)
+}
+
+// Request is the full representation of a song, absent optional fields are cleared.
+type Request struct {
+	GroupName   string     `json:"group_name" validate:"required"`
+	SongName    string     `json:"song_name" validate:"required"`
+	Text        string     `json:"text"`
+	ReleaseDate views.Date `json:"release_date" swaggertype:"string" format:"date" example:"2006-07-16"`
+	Link        string     `json:"link" validate:"omitempty,url"`
+}
+
+type Response struct {
+	response.Response
+	Song views.Song `json:"song"`
+}
+
+// @Summary Replace a song
+// @Description Replace all fields of a song. Absent text and link are cleared,
+// @Description a song without release date inherits it from its album.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "Song ID"
+// @Param If-Match header string true "ETag of the current song or *"
+// @Param request body Request true "Song details"
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /songs/{id} [put]
+func New(ctx context.Context, log *slog.Logger, songUpdater SongUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/songs/replace.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrInvalidId.Error()))
+			return
+		}
+
+		version, ok := handlers.IfMatch(w, r, log, op)
+		if !ok {
+			return
+		}
+
+		var req Request
+		if err = render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error(songs.ErrDecodeRequest.Error(),
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrDecodeRequest.Error()))
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.Error("Failed to close request body: %v",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+
+		log.Debug("request was successfully decoded", slog.String("op", op), slog.Any("request", req))
+
+		if err = validator.New().Struct(req); err != nil {
+			var validationErrs validator.ValidationErrors
+			errors.As(err, &validationErrs)
+			log.Error("failed to validate request",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.ValidationError(validationErrs))
+			return
+		}
+
+		set := models.Set().
+			Value(models.ColumnGroupName, req.GroupName).
+			Value(models.ColumnSongName, req.SongName).
+			Value(models.ColumnText, req.Text).
+			Value(models.ColumnLink, req.Link)
+		if date := time.Time(req.ReleaseDate); date.IsZero() {
+			set.Value(models.ColumnReleaseDate, nil)
+		} else {
+			set.Value(models.ColumnReleaseDate, date)
+		}
+
+		song, err := songUpdater.UpdateSong(ctx, id, version, set)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, songs.ErrUpdateSong)
+			return
+		}
+
+		view := views.FromSong(song)
+		etag := conditional.VersionETag(song.Version)
+		conditional.SetValidators(w, etag, song.UpdatedAt)
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			view,
+		})
+		return
+	}
+}
@@ -7,16 +7,13 @@ import (
 	"log/slog"
 	"net/http"
 	"rest/internal/lib/api/response"
+	"rest/internal/server/handlers"
 	"rest/internal/server/handlers/songs"
 	"strconv"
 )
 
 type SongDeleter interface {
-	DeleteSong(ctx context.Context, id int64) error
-}
-
-type CacheDeleter interface {
-	Del(ctx context.Context, id int64) error
+	DeleteSong(ctx context.Context, id int64, version int64) error
 }
 
 // @Summary Remove a song
@@ -25,11 +22,16 @@ type CacheDeleter interface {
 // @Accept json
 // @Produce json
 // @Param id path int true "Song ID"
+// @Param If-Match header string true "ETag of the current song or *"
 // @Success 204 {object} nil
 // @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /songs/{id} [delete]
-func New(ctx context.Context, log *slog.Logger, songDeleter SongDeleter, cd CacheDeleter) http.HandlerFunc {
+func New(ctx context.Context, log *slog.Logger, songDeleter SongDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "server/handlers/songs/rm.New"
 
@@ -51,20 +53,15 @@ func New(ctx context.Context, log *slog.Logger, songDeleter SongDeleter, cd Cach
 			return
 		}
 
-		err = songDeleter.DeleteSong(ctx, idInt)
-		if err != nil {
-			log.Error(songs.ErrDeleteSong.Error(), slog.String("op", op), slog.String("error", err.Error()))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(songs.ErrDeleteSong.Error()))
+		version, ok := handlers.IfMatch(w, r, log, op)
+		if !ok {
 			return
 		}
 
-		err = cd.Del(r.Context(), idInt)
+		err = songDeleter.DeleteSong(ctx, idInt, version)
 		if err != nil {
-			log.Error("Failed to remove song from cache",
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
+			handlers.StorageError(w, r, log, op, err, songs.ErrDeleteSong)
+			return
 		}
 
 		render.Status(r, http.StatusNoContent)
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/songs"
+	"rest/pkg/models"
+	"strconv"
+	"strings"
+)
+
+type SongsSearcher interface {
+	SearchSongs(ctx context.Context, q string, page int, limit int) ([]models.SearchResultDTO, error)
+}
+
+type Response struct {
+	response.Response
+	Results []views.SearchResult `json:"results"`
+}
+
+// @Summary Search songs
+// @Description Full-text search by lyrics and song name, results are ordered by relevance and contain highlighted snippets.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query, supports quoted phrases, OR and -exclusion"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of results per page" default(10) maximum(100)
+// @Success 200 {object} Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /songs/search [get]
+func New(ctx context.Context, log *slog.Logger, songsSearcher SongsSearcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/songs/search.New"
+
+		page, limit := 1, 10
+
+		err := r.ParseForm()
+		if err != nil {
+			log.Error("unable to parse form", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+			return
+		}
+
+		q := strings.TrimSpace(r.FormValue("q"))
+		if q == "" {
+			log.Error("unable to parse required q field", slog.String("op", op))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrMissingQuery.Error()))
+			return
+		}
+
+		if r.FormValue("page") != "" {
+			page, err = strconv.Atoi(r.FormValue("page"))
+			if err != nil {
+				log.Error("unable to parse page",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+				return
+			}
+		}
+		if r.FormValue("limit") != "" {
+			limit, err = strconv.Atoi(r.FormValue("limit"))
+			if err != nil {
+				log.Error("unable to parse limit",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(songs.ErrParseRequest.Error()))
+				return
+			}
+		}
+
+		if err = models.ValidatePage(page, limit); err != nil {
+			log.Error("invalid page", slog.String("op", op), slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		results, err := songsSearcher.SearchSongs(ctx, q, page, limit)
+		if err != nil {
+			handlers.StorageError(w, r, log, op, err, songs.ErrSearchSongs)
+			return
+		}
+
+		log.Debug("songs were found", slog.String("op", op), slog.Int("count", len(results)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			views.FromSearchResults(results),
+		})
+		return
+	}
+}
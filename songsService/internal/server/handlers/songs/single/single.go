@@ -0,0 +1,101 @@
+package single
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"rest/internal/cache"
+	"rest/internal/lib/api/conditional"
+	"rest/internal/lib/api/response"
+	"rest/internal/lib/api/views"
+	"rest/internal/server/handlers"
+	"rest/internal/server/handlers/songs"
+	"rest/pkg/models"
+	"strconv"
+)
+
+type SongGetter interface {
+	GetSong(ctx context.Context, id int64) (*models.SongDTO, error)
+}
+
+type SongCache interface {
+	Get(ctx context.Context, id int64) (*models.SongDTO, error)
+	Set(ctx context.Context, song *models.SongDTO) error
+}
+
+type Response struct {
+	response.Response
+	Song views.Song `json:"song"`
+}
+
+// @Summary Get song
+// @Description Retrieve a song by its ID. The song is read from cache and falls back to the database.
+// @Description Responses carry ETag with the version of the song and Last-Modified,
+// @Description If-None-Match and If-Modified-Since are answered with 304. Use the ETag as If-Match of updates.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "Song ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param If-Modified-Since header string false "Last-Modified of the cached representation"
+// @Success 200 {object} Response
+// @Success 304 {object} nil
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /songs/{id} [get]
+func New(ctx context.Context, log *slog.Logger, songGetter SongGetter, songCache SongCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "server/handlers/songs/single.New"
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id parameter",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(songs.ErrInvalidId.Error()))
+			return
+		}
+
+		song, err := songCache.Get(ctx, id)
+		if err != nil {
+			if !errors.Is(err, cache.ErrMiss) {
+				log.Error("unable to get song from cache", slog.String("op", op), slog.String("error", err.Error()))
+			}
+
+			song, err = songGetter.GetSong(ctx, id)
+			if err != nil {
+				handlers.StorageError(w, r, log, op, err, songs.ErrGetSong)
+				return
+			}
+
+			if err = songCache.Set(ctx, song); err != nil {
+				log.Error("unable to put song to cache", slog.String("op", op), slog.String("error", err.Error()))
+			}
+			log.Debug("song was received", slog.String("op", op), slog.Int64("id", id))
+		} else {
+			log.Debug("song from cache was received", slog.String("op", op), slog.Int64("id", id))
+		}
+
+		view := views.FromSong(song)
+		etag := conditional.VersionETag(song.Version)
+		conditional.SetValidators(w, etag, song.UpdatedAt)
+
+		if conditional.NotModified(r, etag, song.UpdatedAt) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, Response{
+			response.OK(),
+			view,
+		})
+		return
+	}
+}
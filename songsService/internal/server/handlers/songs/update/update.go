@@ -7,18 +7,16 @@ import (
 	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
+	"rest/internal/lib/api/conditional"
 	"rest/internal/lib/api/response"
 	tp "rest/internal/lib/timeParser"
+	"rest/internal/server/handlers"
 	"rest/internal/server/handlers/songs"
 	"rest/pkg/models"
 )
 
 type SongUpdater interface {
-	UpdateSong(ctx context.Context, songDTO *models.SongDTO, opts ...models.OptionFunc) error
-}
-
-type CacheUpdater interface {
-	Update(ctx context.Context, song *models.SongDTO) error
+	UpdateSong(ctx context.Context, id int64, version int64, set *models.SetBuilder) (*models.SongDTO, error)
 }
 
 type Request struct {
@@ -31,19 +29,30 @@ type Request struct {
 }
 
 // @Summary Update a song
-// @Description Update a song's details by its ID.
+// @Description Update a song's details by its ID, empty fields are left unchanged.
+// @Description Use PATCH /songs/{id} to clear fields and PUT /songs/{id} to replace the song.
+// @Deprecated
 // @Tags songs
 // @Accept json
 // @Produce json
+// @Param If-Match header string true "ETag of the current song or *"
 // @Param request body Request true "Updated song details"
 // @Success 204 {object} nil
 // @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /songs [put]
-func New(ctx context.Context, log *slog.Logger, songUpdater SongUpdater, cd CacheUpdater) http.HandlerFunc {
+func New(ctx context.Context, log *slog.Logger, songUpdater SongUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "server/handlers/songs/update.New"
 
+		version, ok := handlers.IfMatch(w, r, log, op)
+		if !ok {
+			return
+		}
+
 		var req Request
 
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
@@ -82,52 +91,40 @@ func New(ctx context.Context, log *slog.Logger, songUpdater SongUpdater, cd Cach
 			return
 		}
 
-		date, err := tp.ParseToDate(req.ReleaseDate)
-		if err != nil {
-			log.Error("failed to parse release date",
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid release date"))
-			return
+		set := models.Set()
+		if req.GroupName != "" {
+			set.Value(models.ColumnGroupName, req.GroupName)
 		}
-
-		songDTO := &models.SongDTO{
-			Id:          req.Id,
-			GroupName:   req.GroupName,
-			SongName:    req.SongName,
-			Text:        req.Text,
-			ReleaseDate: date,
-			Link:        req.Link,
+		if req.SongName != "" {
+			set.Value(models.ColumnSongName, req.SongName)
+		}
+		if req.ReleaseDate != "" {
+			date, err := tp.ParseToDate(req.ReleaseDate)
+			if err != nil {
+				log.Error("failed to parse release date",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid release date"))
+				return
+			}
+			set.Value(models.ColumnReleaseDate, date)
+		}
+		if req.Link != "" {
+			set.Value(models.ColumnLink, req.Link)
+		}
+		if req.Text != "" {
+			set.Value(models.ColumnText, req.Text)
 		}
 
-		err = songUpdater.UpdateSong(
-			ctx,
-			songDTO,
-			models.WithGroupName(),
-			models.WithSongName(),
-			models.WithReleaseDate(),
-			models.WithLink(),
-			models.WithSongText(),
-		)
-
+		song, err := songUpdater.UpdateSong(ctx, req.Id, version, set)
 		if err != nil {
-			log.Error(songs.ErrUpdateSong.Error(),
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(songs.ErrUpdateSong.Error()))
+			handlers.StorageError(w, r, log, op, err, songs.ErrUpdateSong)
 			return
 		}
 
-		if err = cd.Update(ctx, songDTO); err != nil {
-			log.Error("failed to update song data in cache",
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
-		}
+		conditional.SetValidators(w, conditional.VersionETag(song.Version), song.UpdatedAt)
 
 		render.Status(r, http.StatusNoContent)
 		render.JSON(w, r, nil)
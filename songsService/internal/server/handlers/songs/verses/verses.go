@@ -2,11 +2,15 @@ package verses
 
 import (
 	"context"
+	"errors"
 	"github.com/go-chi/render"
 	"log/slog"
 	"net/http"
+	"rest/internal/cache"
 	"rest/internal/lib/api/response"
+	"rest/internal/server/handlers"
 	"rest/internal/server/handlers/songs"
+	"rest/pkg/models"
 	"strconv"
 )
 
@@ -14,13 +18,24 @@ type VerseGetter interface {
 	GetVerses(ctx context.Context, id int64, offset, limit int) (string, error)
 }
 
+type SongStorage interface {
+	VerseGetter
+	GetSong(ctx context.Context, id int64) (*models.SongDTO, error)
+}
+
+type VerseCache interface {
+	VerseGetter
+	Set(ctx context.Context, song *models.SongDTO) error
+}
+
 type Response struct {
 	response.Response
 	Verses string `json:"verses"`
 }
 
 // @Summary Get song verses
-// @Description Retrieve verses of a song by its ID with optional pagination.
+// @Description Retrieve verses of a song by its ID with optional pagination. Verses are read from cache,
+// @Description songs missing in cache are read from the database and cached. Pages after the end of the song are empty.
 // @Tags songs
 // @Accept json
 // @Produce json
@@ -29,9 +44,11 @@ type Response struct {
 // @Param limit query int false "Number of verses to retrieve" default(1)
 // @Success 200 {object} Response
 // @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /verses [get]
-func New(ctx context.Context, log *slog.Logger, verseGetter VerseGetter, cache VerseGetter) http.HandlerFunc {
+func New(ctx context.Context, log *slog.Logger, songStorage SongStorage, verseCache VerseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "server/handlers/songs/verses.New"
 		log.Debug("Start handling...", slog.String("op", op))
@@ -94,24 +111,30 @@ func New(ctx context.Context, log *slog.Logger, verseGetter VerseGetter, cache V
 			return
 		}
 
-		resultVerses, err := cache.GetVerses(ctx, id, verse, limit)
+		resultVerses, err := verseCache.GetVerses(ctx, id, verse, limit)
 		if err != nil {
-			log.Error("unable to get verses from cache",
-				slog.String("op", op),
-				slog.String("error", err.Error()),
-			)
-
-			resultVerses, err = verseGetter.GetVerses(ctx, id, verse, limit)
-			if err != nil {
-				log.Error("unable to get verses",
+			if !errors.Is(err, cache.ErrMiss) {
+				log.Error("unable to get verses from cache",
 					slog.String("op", op),
 					slog.String("error", err.Error()),
 				)
-				render.Status(r, http.StatusInternalServerError)
-				render.JSON(w, r, response.Error(songs.ErrGetVerses.Error()))
+			}
+
+			resultVerses, err = songStorage.GetVerses(ctx, id, verse, limit)
+			if err != nil {
+				handlers.StorageError(w, r, log, op, err, songs.ErrGetVerses)
 				return
 			}
 
+			// the next requests of the song are served from cache
+			song, err := songStorage.GetSong(ctx, id)
+			if err == nil {
+				err = verseCache.Set(ctx, song)
+			}
+			if err != nil {
+				log.Error("unable to put song to cache", slog.String("op", op), slog.String("error", err.Error()))
+			}
+
 			log.Debug("verses were received", slog.String("op", op))
 
 			render.Status(r, http.StatusOK)
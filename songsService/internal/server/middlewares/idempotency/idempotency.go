@@ -0,0 +1,138 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"io"
+	"log/slog"
+	"net/http"
+	"rest/internal/lib/api/response"
+	"rest/pkg/models"
+)
+
+const (
+	// Header is the request header carrying the client-generated idempotency key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from the store.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// replayedHeaders are response headers stored along with the body, the rest are either set by the server
+// on every response or do not describe the result of the request.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Preference-Applied"}
+
+type Store interface {
+	ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*models.IdempotencyRecordDTO, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, status int, headers map[string][]string, response []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// New returns middleware that makes requests with Idempotency-Key header safe to retry:
+// the response to the first request is stored and replayed for every retry with the same key and body.
+// Server errors are not stored, so such requests are executed again on retry.
+func New(log *slog.Logger, store Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middlewares/idempotency"),
+		)
+
+		mwFunc := func(w http.ResponseWriter, r *http.Request) {
+			const op = "server/middlewares/idempotency.New"
+
+			key := r.Header.Get(Header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("Idempotency-Key header is too long"))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				log.Error("unable to read request body", slog.String("op", op), slog.String("error", err.Error()))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("unable to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := sha256.New()
+			hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+			hash.Write(body)
+			requestHash := hex.EncodeToString(hash.Sum(nil))
+
+			record, err := store.ReserveIdempotencyKey(r.Context(), key, requestHash)
+			if err != nil {
+				log.Error("unable to reserve idempotency key", slog.String("op", op), slog.String("error", err.Error()))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("error processing Idempotency-Key"))
+				return
+			}
+
+			if record != nil {
+				switch {
+				case record.RequestHash != requestHash:
+					render.Status(r, http.StatusUnprocessableEntity)
+					render.JSON(w, r, response.Error("Idempotency-Key was already used with another request"))
+				case record.Status == 0:
+					render.Status(r, http.StatusConflict)
+					render.JSON(w, r, response.Error("request with this Idempotency-Key is in progress"))
+				default:
+					log.Debug("response was replayed", slog.String("op", op), slog.String("key", key))
+					w.Header().Set("Content-Type", "application/json")
+					for name, values := range record.Headers {
+						w.Header()[name] = values
+					}
+					w.Header().Set(ReplayedHeader, "true")
+					w.WriteHeader(record.Status)
+					_, _ = w.Write(record.Response)
+				}
+				return
+			}
+
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			// the request is done, the response is stored also when the client has gone,
+			// so its retry gets the response instead of executing the request again
+			ctx := context.WithoutCancel(r.Context())
+			if status >= http.StatusInternalServerError {
+				err = store.ReleaseIdempotencyKey(ctx, key)
+			} else {
+				err = store.CompleteIdempotencyKey(ctx, key, status, storedHeaders(ww.Header()), buf.Bytes())
+			}
+			if err != nil {
+				log.Error("unable to store idempotency key", slog.String("op", op), slog.String("error", err.Error()))
+			}
+		}
+		return http.HandlerFunc(mwFunc)
+	}
+}
+
+// storedHeaders returns the headers of the response which are replayed with it.
+func storedHeaders(header http.Header) map[string][]string {
+	stored := make(map[string][]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) != 0 {
+			stored[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return stored
+}
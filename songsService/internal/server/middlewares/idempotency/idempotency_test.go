@@ -0,0 +1,167 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"rest/pkg/models"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotencyRecordDTO
+}
+
+func (s *memoryStore) ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*models.IdempotencyRecordDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		copied := *record
+		return &copied, nil
+	}
+	s.records[key] = &models.IdempotencyRecordDTO{RequestHash: requestHash}
+	return nil, nil
+}
+
+func (s *memoryStore) CompleteIdempotencyKey(ctx context.Context, key string, status int, headers map[string][]string,
+	response []byte) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Status, record.Headers, record.Response = status, headers, response
+	return nil
+}
+
+func (s *memoryStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+func TestReplayRestoresHeaders(t *testing.T) {
+	store := &memoryStore{records: map[string]*models.IdempotencyRecordDTO{}}
+	calls := 0
+	handler := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Location", "/jobs/42")
+			w.Header().Set("Preference-Applied", "respond-async")
+			w.Header().Set("X-Request-Id", "not-replayed")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status":"OK","id":7,"job_id":42}`))
+		}))
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(`{"group_name":"Muse","song_name":"Uprising"}`))
+		req.Header.Set(Header, "key")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send()
+	replayed := send()
+
+	if calls != 1 {
+		t.Fatalf("handler was called %d times, want 1", calls)
+	}
+	if replayed.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", replayed.Code, http.StatusAccepted)
+	}
+	if replayed.Body.String() != first.Body.String() {
+		t.Errorf("body = %q, want %q", replayed.Body.String(), first.Body.String())
+	}
+	for _, name := range []string{"Content-Type", "Location", "Preference-Applied"} {
+		if got, want := replayed.Header().Get(name), first.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if got := replayed.Header().Get("X-Request-Id"); got != "" {
+		t.Errorf("X-Request-Id = %q, want it not to be replayed", got)
+	}
+	if got := replayed.Header().Get(ReplayedHeader); got != "true" {
+		t.Errorf("%s = %q, want true", ReplayedHeader, got)
+	}
+}
+
+func TestServerErrorIsNotStored(t *testing.T) {
+	store := &memoryStore{records: map[string]*models.IdempotencyRecordDTO{}}
+	calls := 0
+	handler := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(`{}`))
+		req.Header.Set(Header, "key")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if calls != 2 {
+		t.Errorf("handler was called %d times, want 2", calls)
+	}
+}
+
+// ctxStore fails the calls made with a done context, like the database driver does.
+type ctxStore struct {
+	memoryStore
+}
+
+func (s *ctxStore) CompleteIdempotencyKey(ctx context.Context, key string, status int, headers map[string][]string,
+	response []byte) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.memoryStore.CompleteIdempotencyKey(ctx, key, status, headers, response)
+}
+
+func TestResponseIsStoredAfterClientHasGone(t *testing.T) {
+	store := &ctxStore{memoryStore{records: map[string]*models.IdempotencyRecordDTO{}}}
+	calls := 0
+	handler := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"status":"OK","id":7}`))
+		}))
+
+	send := func(ctx context.Context) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/songs", strings.NewReader(`{}`))
+		req.Header.Set(Header, "key")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// the client disconnects while the song is being added
+	ctx, cancel := context.WithCancel(context.Background())
+	handler = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			cancel()
+		})
+	}(handler)
+	_ = send(ctx)
+
+	replayed := send(context.Background())
+	if calls != 1 {
+		t.Errorf("handler was called %d times, want 1", calls)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("retry got %d replayed=%q, want replayed 201", replayed.Code, replayed.Header().Get(ReplayedHeader))
+	}
+}
@@ -0,0 +1,161 @@
+// Package cached wraps the postgres storage, so every call changing songs invalidates or refreshes
+// their cache entries once the change is committed. Handlers mutate songs through it and never touch cache
+// themselves, reads are inherited from the wrapped storage unchanged.
+package cached
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"rest/internal/storage"
+	"rest/internal/storage/postgres"
+	"rest/pkg/models"
+	"slices"
+)
+
+type Cache interface {
+	Update(ctx context.Context, song *models.SongDTO) error
+	Del(ctx context.Context, ids ...int64) error
+}
+
+// Store is the part of the postgres storage changing songs, the calls are made through it, so they can be stubbed.
+type Store interface {
+	AddSong(ctx context.Context, songDTO *models.SongDTO) (int64, error)
+	AddPendingSong(ctx context.Context, songDTO *models.SongDTO) (int64, int64, error)
+	UpdateSong(ctx context.Context, id int64, version int64, set *models.SetBuilder) (*models.SongDTO, error)
+	DeleteSong(ctx context.Context, id int64, version int64) error
+	ImportSongs(ctx context.Context, rows []models.ImportRowDTO) (map[int]int64, error)
+	AttachSongs(ctx context.Context, albumId int64, songIds []int64) error
+	RenameArtist(ctx context.Context, id int64, name string) error
+	MergeArtists(ctx context.Context, targetId int64, sourceIds []int64) error
+	GetArtist(ctx context.Context, id int64) (*models.ArtistDTO, error)
+}
+
+type Storage struct {
+	*postgres.Storage
+	store Store
+	log   *slog.Logger
+	cache Cache
+}
+
+func New(log *slog.Logger, db *postgres.Storage, cache Cache) *Storage {
+	return &Storage{Storage: db, store: db, log: log, cache: cache}
+}
+
+// AddSong leaves cache as it is, ids are never reused, so there is no entry of the new song to invalidate
+// and the song is cacheable right away.
+func (s *Storage) AddSong(ctx context.Context, songDTO *models.SongDTO) (int64, error) {
+	return s.store.AddSong(ctx, songDTO)
+}
+
+// AddPendingSong leaves cache as it is, see AddSong.
+func (s *Storage) AddPendingSong(ctx context.Context, songDTO *models.SongDTO) (int64, int64, error) {
+	return s.store.AddPendingSong(ctx, songDTO)
+}
+
+// UpdateSong refreshes the cached song with its new version, the entry is removed when the refresh fails.
+func (s *Storage) UpdateSong(ctx context.Context, id int64, version int64,
+	set *models.SetBuilder) (*models.SongDTO, error) {
+
+	const op = "storage/cached.UpdateSong"
+
+	song, err := s.store.UpdateSong(ctx, id, version, set)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.cache.Update(ctx, song); err != nil {
+		s.log.Error("failed to refresh song in cache",
+			slog.String("op", op),
+			slog.Int64("id", id),
+			slog.String("error", err.Error()),
+		)
+		s.invalidate(ctx, op, id)
+	}
+
+	return song, nil
+}
+
+// DeleteSong removes the song from cache also when it is already missing in the database,
+// the entry left by whoever has deleted it is stale anyway.
+func (s *Storage) DeleteSong(ctx context.Context, id int64, version int64) error {
+	err := s.store.DeleteSong(ctx, id, version)
+	if err != nil && !errors.Is(err, storage.ErrSongNotFound) {
+		return err
+	}
+
+	s.invalidate(ctx, "storage/cached.DeleteSong", id)
+	return err
+}
+
+func (s *Storage) ImportSongs(ctx context.Context, rows []models.ImportRowDTO) (map[int]int64, error) {
+	ids, err := s.store.ImportSongs(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "storage/cached.ImportSongs", slices.Collect(maps.Values(ids))...)
+	return ids, nil
+}
+
+// AttachSongs invalidates the songs, they may inherit the release date of the album.
+func (s *Storage) AttachSongs(ctx context.Context, albumId int64, songIds []int64) error {
+	if err := s.store.AttachSongs(ctx, albumId, songIds); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx, "storage/cached.AttachSongs", songIds...)
+	return nil
+}
+
+// RenameArtist invalidates songs of the artist, their group name has changed.
+func (s *Storage) RenameArtist(ctx context.Context, id int64, name string) error {
+	if err := s.store.RenameArtist(ctx, id, name); err != nil {
+		return err
+	}
+
+	s.invalidateArtist(ctx, "storage/cached.RenameArtist", id)
+	return nil
+}
+
+// MergeArtists invalidates songs of the target artist, the moved songs are among them.
+func (s *Storage) MergeArtists(ctx context.Context, targetId int64, sourceIds []int64) error {
+	if err := s.store.MergeArtists(ctx, targetId, sourceIds); err != nil {
+		return err
+	}
+
+	s.invalidateArtist(ctx, "storage/cached.MergeArtists", targetId)
+	return nil
+}
+
+// invalidateArtist removes all songs of the artist from cache.
+func (s *Storage) invalidateArtist(ctx context.Context, op string, artistId int64) {
+	artist, err := s.store.GetArtist(ctx, artistId)
+	if err != nil {
+		s.log.Error("failed to get songs of artist to invalidate cache",
+			slog.String("op", op),
+			slog.Int64("artist_id", artistId),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	ids := make([]int64, 0, len(artist.Songs))
+	for _, song := range artist.Songs {
+		ids = append(ids, song.Id)
+	}
+	s.invalidate(ctx, op, ids...)
+}
+
+// invalidate removes the songs from cache, the change is already committed,
+// so the failure is only logged.
+func (s *Storage) invalidate(ctx context.Context, op string, ids ...int64) {
+	if err := s.cache.Del(ctx, ids...); err != nil {
+		s.log.Error("failed to invalidate songs in cache",
+			slog.String("op", op),
+			slog.Any("ids", ids),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,232 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"io"
+	"log/slog"
+	rd "rest/internal/cache/redis"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeStore keeps songs in memory, ids are assigned from nextId.
+type fakeStore struct {
+	songs     map[int64]*models.SongDTO
+	nextId    int64
+	deleteErr error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{songs: map[int64]*models.SongDTO{}, nextId: 42}
+}
+
+func (f *fakeStore) AddSong(ctx context.Context, songDTO *models.SongDTO) (int64, error) {
+	id := f.nextId
+	f.nextId++
+
+	song := *songDTO
+	song.Id, song.Version, song.UpdatedAt = id, 1, time.Now()
+	f.songs[id] = &song
+	return id, nil
+}
+
+func (f *fakeStore) AddPendingSong(ctx context.Context, songDTO *models.SongDTO) (int64, int64, error) {
+	id, err := f.AddSong(ctx, songDTO)
+	return id, 1, err
+}
+
+func (f *fakeStore) UpdateSong(ctx context.Context, id int64, version int64,
+	set *models.SetBuilder) (*models.SongDTO, error) {
+
+	song, ok := f.songs[id]
+	if !ok {
+		return nil, storage.ErrSongNotFound
+	}
+	if version != 0 && song.Version != version {
+		return nil, storage.ErrVersionMismatch
+	}
+	updated := *song
+	if text, ok := set.Lookup(models.ColumnText); ok {
+		updated.Text = text.(string)
+	}
+	updated.Version++
+	updated.UpdatedAt = time.Now()
+	f.songs[id] = &updated
+
+	copied := updated
+	return &copied, nil
+}
+
+func (f *fakeStore) DeleteSong(ctx context.Context, id int64, version int64) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	if _, ok := f.songs[id]; !ok {
+		return storage.ErrSongNotFound
+	}
+	delete(f.songs, id)
+	return nil
+}
+
+func (f *fakeStore) get(id int64) (*models.SongDTO, error) {
+	song, ok := f.songs[id]
+	if !ok {
+		return nil, storage.ErrSongNotFound
+	}
+	copied := *song
+	return &copied, nil
+}
+
+func (f *fakeStore) ImportSongs(ctx context.Context, rows []models.ImportRowDTO) (map[int]int64, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) AttachSongs(ctx context.Context, albumId int64, songIds []int64) error {
+	return nil
+}
+
+func (f *fakeStore) RenameArtist(ctx context.Context, id int64, name string) error {
+	return nil
+}
+
+func (f *fakeStore) MergeArtists(ctx context.Context, targetId int64, sourceIds []int64) error {
+	return nil
+}
+
+func (f *fakeStore) GetArtist(ctx context.Context, id int64) (*models.ArtistDTO, error) {
+	return nil, storage.ErrArtistNotFound
+}
+
+func newTestStorage(t *testing.T) (*Storage, *fakeStore, *rd.Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	cache := rd.New(rd.Options{Address: mr.Addr()})
+	db := newFakeStore()
+
+	return &Storage{store: db, log: slog.New(slog.NewTextHandler(io.Discard, nil)), cache: cache}, db, cache, mr
+}
+
+func TestAddedSongIsCacheable(t *testing.T) {
+	ctx := context.Background()
+	s, db, cache, mr := newTestStorage(t)
+
+	id, err := s.AddSong(ctx, &models.SongDTO{GroupName: "Muse", SongName: "Uprising"})
+	if err != nil {
+		t.Fatalf("AddSong: %v", err)
+	}
+	if id != 42 {
+		t.Fatalf("id = %d, want 42", id)
+	}
+
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys = %v, want cache untouched", keys)
+	}
+
+	// the first read caches the song under its id right away
+	song, _ := db.get(id)
+	if err = cache.Set(ctx, song); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !mr.Exists("songs:42") {
+		t.Errorf("keys = %v, want songs:42", mr.Keys())
+	}
+	if got := mr.HGet("songs:42", "song_name"); got != "Uprising" {
+		t.Errorf("song_name = %q, want Uprising", got)
+	}
+}
+
+func TestReaderCanNotCacheDeletedSong(t *testing.T) {
+	ctx := context.Background()
+	s, db, cache, _ := newTestStorage(t)
+
+	id, _ := s.AddSong(ctx, &models.SongDTO{GroupName: "Muse", SongName: "Uprising"})
+	// the reader gets the song from the database, then the song is deleted before the reader caches it
+	stale, _ := db.get(id)
+
+	if err := s.DeleteSong(ctx, id, 1); err != nil {
+		t.Fatalf("DeleteSong: %v", err)
+	}
+	if err := cache.Set(ctx, stale); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if song, err := cache.Get(ctx, id); err == nil {
+		t.Errorf("deleted song is served from cache: %+v", song)
+	}
+}
+
+func TestReaderCanNotCacheOldVersion(t *testing.T) {
+	ctx := context.Background()
+	s, db, cache, _ := newTestStorage(t)
+
+	id, _ := s.AddSong(ctx, &models.SongDTO{GroupName: "Muse", SongName: "Uprising", Text: "old"})
+
+	// the reader gets the song from the database, then the song is updated before the reader caches it
+	stale, _ := db.get(id)
+
+	updated, err := s.UpdateSong(ctx, id, 1, models.Set().Value(models.ColumnText, "new"))
+	if err != nil {
+		t.Fatalf("UpdateSong: %v", err)
+	}
+	if err = cache.Set(ctx, stale); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, err := cache.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Version != updated.Version || got.Text != "new" {
+		t.Errorf("Get = version %d text %q, want version %d text new", got.Version, got.Text, updated.Version)
+	}
+}
+
+func TestDeleteMissingSongInvalidatesCache(t *testing.T) {
+	ctx := context.Background()
+	s, _, cache, mr := newTestStorage(t)
+
+	// the entry is left by whoever has deleted the song from the database
+	song := &models.SongDTO{Id: 7, GroupName: "Muse", SongName: "Uprising", Version: 1, UpdatedAt: time.Now()}
+	if err := cache.Set(ctx, song); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	err := s.DeleteSong(ctx, 7, 1)
+	if !errors.Is(err, storage.ErrSongNotFound) {
+		t.Errorf("DeleteSong = %v, want ErrSongNotFound", err)
+	}
+	if mr.Exists("songs:7") {
+		t.Error("entry of the missing song was kept")
+	}
+	if !mr.Exists("songs:deleted:7") {
+		t.Error("missing song was not tombstoned")
+	}
+	if _, err = cache.Get(ctx, 7); err == nil {
+		t.Error("missing song is served from cache")
+	}
+}
+
+func TestFailedDeleteKeepsCache(t *testing.T) {
+	ctx := context.Background()
+	s, db, cache, mr := newTestStorage(t)
+
+	id, _ := s.AddSong(ctx, &models.SongDTO{GroupName: "Muse", SongName: "Uprising"})
+	song, _ := db.get(id)
+	_ = cache.Set(ctx, song)
+
+	// the version check fails, the song stays as it is
+	db.songs[id].Version = 2
+	db.deleteErr = storage.ErrVersionMismatch
+	if err := s.DeleteSong(ctx, id, 1); !errors.Is(err, storage.ErrVersionMismatch) {
+		t.Fatalf("DeleteSong = %v, want ErrVersionMismatch", err)
+	}
+	if mr.Exists("songs:deleted:" + strconv.FormatInt(id, 10)) {
+		t.Error("song was tombstoned although it was not deleted")
+	}
+}
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"time"
+)
+
+func (s *Storage) AddAlbum(ctx context.Context, albumDTO *models.AlbumDTO) (int64, error) {
+	const op = "storage/postgres.AddAlbum"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, wrapError(op, err)
+	}
+
+	artistId, err := upsertArtist(ctx, tx, albumDTO.GroupName)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return 0, wrapError(op, err)
+	}
+
+	const query = `INSERT INTO albums(title, artist_id, release_date, cover_link)
+                   VALUES ($1, $2, $3, $4) RETURNING id;`
+	var id int64
+	err = tx.QueryRow(ctx, query, albumDTO.Title, artistId, nullableDate(albumDTO.ReleaseDate),
+		albumDTO.CoverLink).Scan(&id)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return 0, wrapError(op, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return 0, wrapError(op+": failed to commit transaction", err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetAlbum(ctx context.Context, id int64) (*models.AlbumDTO, error) {
+	const op = "storage/postgres.GetAlbum"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const albumQuery = `SELECT albums.id, albums.title, artists.name, albums.release_date, albums.cover_link
+                        FROM albums JOIN artists ON artists.id = albums.artist_id
+                        WHERE albums.id=$1`
+
+	album := models.AlbumDTO{Tracks: make([]models.TrackDTO, 0)}
+	var releaseDate *time.Time
+	err := s.dbPool.QueryRow(ctx, albumQuery, id).
+		Scan(&album.Id, &album.Title, &album.GroupName, &releaseDate, &album.CoverLink)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrAlbumNotFound
+	}
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	if releaseDate != nil {
+		album.ReleaseDate = *releaseDate
+	}
+
+	query := `SELECT ` + songColumns + `, track_number FROM ` + songsSource +
+		` WHERE album_id=$1 ORDER BY track_number`
+	rows, err := s.dbPool.Query(ctx, query, id)
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var track models.TrackDTO
+		if err = scanSong(rows, &track.Song, &track.Number); err != nil {
+			return nil, wrapError(op, err)
+		}
+		album.Tracks = append(album.Tracks, track)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return &album, nil
+}
+
+// AttachSongs appends songs to the end of the album tracklist in the given order.
+// Songs which already belong to another album are moved to this one, repeated ids are attached once.
+func (s *Storage) AttachSongs(ctx context.Context, albumId int64, songIds []int64) error {
+	const op = "storage/postgres.AttachSongs"
+
+	// every song is updated once, duplicates would make the count of updated rows fall short of the ids
+	songIds = uniqueIds(songIds)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if err = lockAlbum(ctx, tx, albumId); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	albumIds := []int64{albumId}
+	rows, err := tx.Query(ctx, `SELECT DISTINCT album_id FROM songs WHERE id = ANY($1) AND album_id IS NOT NULL`,
+		songIds)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	previousAlbumIds, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	albumIds = append(albumIds, previousAlbumIds...)
+
+	var lastTrack int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(track_number), 0) FROM songs WHERE album_id=$1 AND NOT id = ANY($2)`,
+		albumId, songIds).Scan(&lastTrack)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	const query = `UPDATE songs SET album_id=$1, track_number=$2 + tracks.position,
+                       updated_at=now(), version=version+1
+                   FROM unnest($3::bigint[]) WITH ORDINALITY AS tracks(id, position)
+                   WHERE songs.id = tracks.id`
+	res, err := tx.Exec(ctx, query, albumId, lastTrack, songIds)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	if res.RowsAffected() != int64(len(songIds)) {
+		transactionRollback(ctx, tx, op)
+		return storage.ErrSongNotFound
+	}
+
+	if err = renumberTracks(ctx, tx, albumIds); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op+": failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// ReorderTracks sets the tracklist order of the album, songIds must contain every song of the album exactly once.
+func (s *Storage) ReorderTracks(ctx context.Context, albumId int64, songIds []int64) error {
+	const op = "storage/postgres.ReorderTracks"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if err = lockAlbum(ctx, tx, albumId); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	var tracksCount int
+	err = tx.QueryRow(ctx, `SELECT count(*) FROM songs WHERE album_id=$1`, albumId).Scan(&tracksCount)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	if tracksCount != len(songIds) {
+		transactionRollback(ctx, tx, op)
+		return storage.ErrTracksMismatch
+	}
+
+	const query = `UPDATE songs SET track_number=tracks.position
+                   FROM unnest($2::bigint[]) WITH ORDINALITY AS tracks(id, position)
+                   WHERE songs.id = tracks.id AND songs.album_id=$1`
+	res, err := tx.Exec(ctx, query, albumId, songIds)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	if res.RowsAffected() != int64(len(songIds)) {
+		transactionRollback(ctx, tx, op)
+		return storage.ErrTracksMismatch
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op+": failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// lockAlbum locks the album row until the end of transaction, so concurrent tracklist changes are serialized.
+func lockAlbum(ctx context.Context, tx pgx.Tx, albumId int64) error {
+	var id int64
+	err := tx.QueryRow(ctx, `SELECT id FROM albums WHERE id=$1 FOR UPDATE`, albumId).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrAlbumNotFound
+	}
+
+	return err
+}
+
+// renumberTracks removes gaps in the tracklists of the given albums keeping the tracks order.
+func renumberTracks(ctx context.Context, tx pgx.Tx, albumIds []int64) error {
+	const query = `UPDATE songs SET track_number=tracks.position
+                   FROM (SELECT id, row_number() OVER (PARTITION BY album_id ORDER BY track_number) AS position
+                         FROM songs WHERE album_id = ANY($1)) AS tracks
+                   WHERE songs.id = tracks.id AND songs.track_number<>tracks.position`
+	_, err := tx.Exec(ctx, query, albumIds)
+
+	return err
+}
+
+// uniqueIds returns the ids without repetitions, keeping the first occurrence of each.
+func uniqueIds(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUniqueIds(t *testing.T) {
+	tests := []struct {
+		ids  []int64
+		want []int64
+	}{
+		{nil, []int64{}},
+		{[]int64{1, 2, 3}, []int64{1, 2, 3}},
+		{[]int64{3, 1, 3, 2, 1}, []int64{3, 1, 2}},
+		{[]int64{5, 5, 5}, []int64{5}},
+	}
+
+	for _, tt := range tests {
+		if got := uniqueIds(tt.ids); !slices.Equal(got, tt.want) {
+			t.Errorf("uniqueIds(%v) = %v, want %v", tt.ids, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"time"
+)
+
+// upsertArtist returns id of the artist with the given name, the artist is created if it does not exist yet.
+func upsertArtist(ctx context.Context, tx pgx.Tx, name string) (int64, error) {
+	const query = `INSERT INTO artists(name) VALUES (btrim($1))
+                   ON CONFLICT (normalized_name) DO UPDATE SET name = artists.name
+                   RETURNING id;`
+	var id int64
+	if err := tx.QueryRow(ctx, query, name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to upsert artist: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetArtists(ctx context.Context, page int, limit int) ([]models.ArtistDTO, error) {
+	const op = "storage/postgres.GetArtists"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const query = `SELECT id, name FROM artists ORDER BY id OFFSET $1 LIMIT $2`
+
+	rows, err := s.dbPool.Query(ctx, query, (page-1)*limit, limit)
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	defer rows.Close()
+
+	artists := make([]models.ArtistDTO, 0)
+	for rows.Next() {
+		var artist models.ArtistDTO
+		if err = rows.Scan(&artist.Id, &artist.Name); err != nil {
+			return nil, wrapError(op, err)
+		}
+		artists = append(artists, artist)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return artists, nil
+}
+
+func (s *Storage) GetArtist(ctx context.Context, id int64) (*models.ArtistDTO, error) {
+	const op = "storage/postgres.GetArtist"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	artist := models.ArtistDTO{Songs: make([]models.SongDTO, 0)}
+	err := s.dbPool.QueryRow(ctx, `SELECT id, name FROM artists WHERE id=$1`, id).Scan(&artist.Id, &artist.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrArtistNotFound
+	}
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	query := `SELECT ` + songColumns + ` FROM ` + songsSource +
+		` WHERE artist_id=$1 ORDER BY id`
+	rows, err := s.dbPool.Query(ctx, query, id)
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song models.SongDTO
+		if err = scanSong(rows, &song); err != nil {
+			return nil, wrapError(op, err)
+		}
+		artist.Songs = append(artist.Songs, song)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return &artist, nil
+}
+
+// RenameArtist changes the name of the artist, songs of the artist get new versions since their group name changes.
+func (s *Storage) RenameArtist(ctx context.Context, id int64, name string) error {
+	const op = "storage/postgres.RenameArtist"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	res, err := tx.Exec(ctx, `UPDATE artists SET name=btrim($1) WHERE id=$2`, name, id)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return storage.ErrArtistExists
+		}
+		return wrapError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		transactionRollback(ctx, tx, op)
+		return storage.ErrArtistNotFound
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE songs SET updated_at=now(), version=version+1 WHERE artist_id=$1`, id)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op+": failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// MergeArtists moves all songs and albums of the source artists to the target artist and removes the source artists.
+func (s *Storage) MergeArtists(ctx context.Context, targetId int64, sourceIds []int64) error {
+	const op = "storage/postgres.MergeArtists"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM artists WHERE id=$1)`, targetId).Scan(&exists)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	if !exists {
+		transactionRollback(ctx, tx, op)
+		return storage.ErrArtistNotFound
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE songs SET artist_id=$1, updated_at=now(), version=version+1 WHERE artist_id = ANY($2) AND artist_id<>$1`,
+		targetId, sourceIds)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE albums SET artist_id=$1 WHERE artist_id = ANY($2) AND artist_id<>$1`,
+		targetId, sourceIds)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+
+	res, err := tx.Exec(ctx, `DELETE FROM artists WHERE id = ANY($1) AND id<>$2`, sourceIds, targetId)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		transactionRollback(ctx, tx, op)
+		return storage.ErrArtistNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return wrapError(op+": failed to commit transaction", err)
+	}
+
+	return nil
+}
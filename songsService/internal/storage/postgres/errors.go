@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"rest/internal/storage"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	uniqueViolationCode           = "23505"
+	exclusionViolationCode        = "23P01"
+	foreignKeyViolationCode       = "23503"
+	notNullViolationCode          = "23502"
+	checkViolationCode            = "23514"
+	stringDataRightTruncationCode = "22001"
+	numericValueOutOfRangeCode    = "22003"
+	datetimeFieldOverflowCode     = "22008"
+	serializationFailureCode      = "40001"
+	deadlockDetectedCode          = "40P01"
+	queryCanceledCode             = "57014"
+)
+
+// constraintErrors are returned instead of generic errors of the kind when the named constraint is violated.
+var constraintErrors = map[string]error{
+	"songs_artist_id_song_name_key": storage.ErrSongExists,
+}
+
+// wrapError wraps err with op, driver errors are converted to storage errors of the matching kind.
+func wrapError(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, classify(err))
+}
+
+func classify(err error) error {
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &storage.Error{Kind: storage.ErrNotFound, Msg: "not found", Err: err}
+	}
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return &storage.Error{Kind: storage.ErrTimeout, Msg: "database did not respond in time", Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	if constraintErr, ok := constraintErrors[pgErr.ConstraintName]; ok {
+		return constraintErr
+	}
+
+	switch pgErr.Code {
+	case uniqueViolationCode, exclusionViolationCode:
+		return &storage.Error{Kind: storage.ErrConflict, Msg: constraintMessage("conflicts with", pgErr), Err: err}
+	case serializationFailureCode, deadlockDetectedCode:
+		return &storage.Error{Kind: storage.ErrConflict, Msg: "concurrent modification, try again", Err: err}
+	case foreignKeyViolationCode, notNullViolationCode, checkViolationCode:
+		return &storage.Error{Kind: storage.ErrConstraint, Msg: constraintMessage("violates", pgErr), Err: err}
+	case stringDataRightTruncationCode, numericValueOutOfRangeCode, datetimeFieldOverflowCode:
+		return &storage.Error{Kind: storage.ErrConstraint, Msg: "value is out of range", Err: err}
+	case queryCanceledCode:
+		return &storage.Error{Kind: storage.ErrTimeout, Msg: "database did not respond in time", Err: err}
+	}
+
+	return err
+}
+
+func constraintMessage(verb string, pgErr *pgconn.PgError) string {
+	if pgErr.ConstraintName == "" {
+		return "data " + verb + " constraint"
+	}
+	return "data " + verb + " constraint " + pgErr.ConstraintName
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"rest/pkg/models"
+	"time"
+)
+
+// ReserveIdempotencyKey reserves the key for the request with the given hash and returns nil,
+// or returns the record stored with the key when it is already reserved.
+// Keys expire after a day and reservations that were not completed within a minute are taken over.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, key string,
+	requestHash string) (*models.IdempotencyRecordDTO, error) {
+
+	const op = "storage/postgres.ReserveIdempotencyKey"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const query = `INSERT INTO idempotency_keys(key, request_hash) VALUES ($1, $2)
+                   ON CONFLICT (key) DO UPDATE
+                       SET request_hash=EXCLUDED.request_hash, status=NULL, headers=NULL, response=NULL, created_at=now()
+                       WHERE idempotency_keys.created_at < now() - interval '1 day'
+                          OR (idempotency_keys.status IS NULL AND idempotency_keys.created_at < now() - interval '1 minute')`
+	res, err := s.dbPool.Exec(ctx, query, key, requestHash)
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	if res.RowsAffected() != 0 {
+		return nil, nil
+	}
+
+	var status *int
+	var record models.IdempotencyRecordDTO
+	err = s.dbPool.QueryRow(ctx, `SELECT request_hash, status, COALESCE(headers, '{}'), response
+                                  FROM idempotency_keys WHERE key=$1`, key).
+		Scan(&record.RequestHash, &status, &record.Headers, &record.Response)
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	if status != nil {
+		record.Status = *status
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request the key was reserved for.
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string, status int, headers map[string][]string,
+	response []byte) error {
+
+	const op = "storage/postgres.CompleteIdempotencyKey"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.dbPool.Exec(ctx, `UPDATE idempotency_keys SET status=$2, headers=$3, response=$4 WHERE key=$1`,
+		key, status, headers, response)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes the reservation, so the request may be retried with the same key.
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	const op = "storage/postgres.ReleaseIdempotencyKey"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.dbPool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key=$1 AND status IS NULL`, key)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"rest/pkg/models"
+	"time"
+)
+
+// ImportSongs copies the rows into a temporary table and inserts songs of missing artists and names from there.
+// Songs that already exist and repeated songs of the batch are skipped, the first line of a song wins.
+// It returns ids of the inserted songs by line.
+func (s *Storage) ImportSongs(ctx context.Context, rows []models.ImportRowDTO) (map[int]int64, error) {
+	const op = "storage/postgres.ImportSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	const createQuery = `CREATE TEMP TABLE import_songs (
+                             line INT NOT NULL,
+                             group_name TEXT NOT NULL,
+                             song_name TEXT NOT NULL,
+                             release_date DATE,
+                             song_text TEXT NOT NULL,
+                             link TEXT NOT NULL
+                         ) ON COMMIT DROP`
+	if _, err = tx.Exec(ctx, createQuery); err != nil {
+		transactionRollback(ctx, tx, op)
+		return nil, wrapError(op, err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"import_songs"},
+		[]string{"line", "group_name", "song_name", "release_date", "song_text", "link"},
+		pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+			song := rows[i].Song
+			return []any{rows[i].Line, song.GroupName, song.SongName, nullableDate(song.ReleaseDate),
+				song.Text, song.Link}, nil
+		}),
+	)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return nil, wrapError(op, err)
+	}
+
+	const artistsQuery = `INSERT INTO artists(name)
+                          SELECT DISTINCT ON (lower(btrim(group_name))) btrim(group_name)
+                          FROM import_songs
+                          ORDER BY lower(btrim(group_name)), line
+                          ON CONFLICT (normalized_name) DO NOTHING`
+	if _, err = tx.Exec(ctx, artistsQuery); err != nil {
+		transactionRollback(ctx, tx, op)
+		return nil, wrapError(op, err)
+	}
+
+	const songsQuery = `WITH source AS (
+                            SELECT DISTINCT ON (artists.id, lower(btrim(import_songs.song_name)))
+                                   import_songs.line, artists.id AS artist_id, import_songs.song_name,
+                                   import_songs.release_date, import_songs.song_text, import_songs.link
+                            FROM import_songs
+                                JOIN artists ON artists.normalized_name = lower(btrim(import_songs.group_name))
+                            ORDER BY artists.id, lower(btrim(import_songs.song_name)), import_songs.line
+                        ), inserted AS (
+                            INSERT INTO songs(artist_id, song_name, release_date, song_text, link)
+                            SELECT artist_id, song_name, release_date, song_text, link FROM source
+                            ON CONFLICT (artist_id, lower(btrim(song_name))) DO NOTHING
+                            RETURNING id, artist_id, lower(btrim(song_name)) AS name_key
+                        )
+                        SELECT source.line, inserted.id
+                        FROM source
+                            JOIN inserted ON inserted.artist_id = source.artist_id
+                                AND inserted.name_key = lower(btrim(source.song_name))`
+	result, err := tx.Query(ctx, songsQuery)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return nil, wrapError(op, err)
+	}
+
+	ids := make(map[int]int64, len(rows))
+	for result.Next() {
+		var line int
+		var id int64
+		if err = result.Scan(&line, &id); err != nil {
+			result.Close()
+			transactionRollback(ctx, tx, op)
+			return nil, wrapError(op, err)
+		}
+		ids[line] = id
+	}
+	result.Close()
+	if err = result.Err(); err != nil {
+		transactionRollback(ctx, tx, op)
+		return nil, wrapError(op, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return nil, wrapError(op+": failed to commit transaction", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"rest/internal/storage"
+	"rest/pkg/models"
+	"time"
+)
+
+const jobColumns = `id, song_id, status, attempts, COALESCE(last_error, ''), created_at, updated_at`
+
+// AddPendingSong adds the song without details and a job to enrich it, returns ids of both.
+func (s *Storage) AddPendingSong(ctx context.Context, songDTO *models.SongDTO) (int64, int64, error) {
+	const op = "storage/postgres.AddPendingSong"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, 0, wrapError(op, err)
+	}
+
+	songId, err := insertSong(ctx, tx, songDTO)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return 0, 0, wrapError(op, err)
+	}
+
+	var jobId int64
+	err = tx.QueryRow(ctx, `INSERT INTO jobs(song_id) VALUES ($1) RETURNING id`, songId).Scan(&jobId)
+	if err != nil {
+		transactionRollback(ctx, tx, op)
+		return 0, 0, wrapError(op, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		transactionRollback(ctx, tx, op)
+		return 0, 0, wrapError(op+": failed to commit transaction", err)
+	}
+
+	return songId, jobId, nil
+}
+
+// GetJob returns the job by id or storage.ErrJobNotFound.
+func (s *Storage) GetJob(ctx context.Context, id int64) (*models.JobDTO, error) {
+	const op = "storage/postgres.GetJob"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	job, err := scanJob(s.dbPool.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id=$1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return job, nil
+}
+
+// ClaimJob marks the next due job as running and returns it, or returns nil when there are no due jobs.
+// Concurrent workers skip jobs claimed by each other. The job is leased for the given duration,
+// when it is neither completed nor failed in time, it is claimed again as if the worker has crashed.
+// Jobs whose last attempt has run out of lease are failed instead, so a job crashing its worker is not retried forever.
+func (s *Storage) ClaimJob(ctx context.Context, lease time.Duration) (*models.JobDTO, error) {
+	const op = "storage/postgres.ClaimJob"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const query = `WITH expired AS (UPDATE jobs SET status='failed', updated_at=now(),
+                                        last_error=COALESCE(last_error, 'lease expired on the last attempt')
+                                    WHERE status='running' AND run_at <= now() AND attempts >= max_attempts),
+                        next AS (SELECT id FROM jobs
+                                 WHERE status IN ('pending', 'running') AND run_at <= now()
+                                       AND attempts < max_attempts
+                                 ORDER BY run_at
+                                 LIMIT 1 FOR UPDATE SKIP LOCKED)
+                   UPDATE jobs SET status='running', attempts=jobs.attempts+1,
+                                   run_at=now() + make_interval(secs => $1), updated_at=now()
+                   FROM next WHERE jobs.id = next.id
+                   RETURNING jobs.id, jobs.song_id, jobs.status, jobs.attempts, COALESCE(jobs.last_error, ''),
+                             jobs.created_at, jobs.updated_at`
+	job, err := scanJob(s.dbPool.QueryRow(ctx, query, lease.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return job, nil
+}
+
+// CompleteJob marks the job as done. The attempt is the one returned by ClaimJob, when the job has been
+// claimed again since then, storage.ErrJobLeaseLost is returned and the result of the other worker is kept.
+func (s *Storage) CompleteJob(ctx context.Context, id int64, attempt int) error {
+	const op = "storage/postgres.CompleteJob"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const query = `UPDATE jobs SET status='done', last_error=NULL, updated_at=now()
+                   WHERE id=$1 AND attempts=$2 AND status='running'`
+	res, err := s.dbPool.Exec(ctx, query, id, attempt)
+	if err != nil {
+		return wrapError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return storage.ErrJobLeaseLost
+	}
+
+	return nil
+}
+
+// FailJob records the error of the attempt and schedules the job to run again after the delay.
+// The job fails for good when it is out of attempts or when retry is false.
+// Like CompleteJob, it returns storage.ErrJobLeaseLost when the attempt is no longer the current one.
+func (s *Storage) FailJob(ctx context.Context, id int64, attempt int, reason string, retry bool,
+	delay time.Duration) error {
+
+	const op = "storage/postgres.FailJob"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	const query = `UPDATE jobs
+                   SET status=CASE WHEN $3 AND attempts < max_attempts THEN 'pending' ELSE 'failed' END,
+                       last_error=$2, run_at=now() + make_interval(secs => $4), updated_at=now()
+                   WHERE id=$1 AND attempts=$5 AND status='running'`
+	res, err := s.dbPool.Exec(ctx, query, id, reason, retry, delay.Seconds(), attempt)
+	if err != nil {
+		return wrapError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return storage.ErrJobLeaseLost
+	}
+
+	return nil
+}
+
+func scanJob(row pgx.Row) (*models.JobDTO, error) {
+	var job models.JobDTO
+	err := row.Scan(&job.Id, &job.SongId, &job.Status, &job.Attempts, &job.LastError, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
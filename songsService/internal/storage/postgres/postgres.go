@@ -2,19 +2,30 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
 	"rest/internal/storage"
 	"rest/pkg/models"
+	"slices"
 	"strings"
 	"time"
 )
 
-const (
-	setOP   = "SET"
-	whereOP = "WHERE"
-)
+// songsSource exposes songs together with the name of their artist as group_name
+// and the release date inherited from the album when the song has no own one,
+// so select-queries and option-funcs can address columns without table prefixes.
+const songsSource = `(SELECT songs.id, songs.artist_id, artists.name AS group_name, songs.song_name,
+                             COALESCE(songs.release_date, albums.release_date) AS release_date,
+                             songs.song_text, songs.link, songs.album_id, songs.track_number,
+                             songs.search_vector, songs.updated_at, songs.version
+                      FROM songs
+                          JOIN artists ON artists.id = songs.artist_id
+                          LEFT JOIN albums ON albums.id = songs.album_id) AS songs`
+
+const songColumns = `id, group_name, song_name, release_date, song_text, link, updated_at, version`
 
 type Storage struct {
 	dbPool *pgxpool.Pool
@@ -30,7 +41,7 @@ func New(ctx context.Context, dsn string) (*Storage, error) {
 	defer cancel()
 	dbPool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrapError(op, err)
 	}
 
 	return &Storage{dbPool: dbPool}, nil
@@ -43,85 +54,206 @@ func (s *Storage) AddSong(ctx context.Context, songDTO *models.SongDTO) (int64,
 
 	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, wrapError(op, err)
 
 	}
 
-	const query = `INSERT INTO songs(group_name, song_name, release_date, song_text, link) 
-                   VALUES ($1, $2, $3, $4, $5) RETURNING id;`
-	var id int64
-	err = tx.QueryRow(ctx, query, songDTO.GroupName, songDTO.SongName, songDTO.ReleaseDate,
-		songDTO.Text, songDTO.Link).Scan(&id)
+	id, err := insertSong(ctx, tx, songDTO)
 	if err != nil {
 		transactionRollback(ctx, tx, op)
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, wrapError(op, err)
 	}
 
 	if err = tx.Commit(ctx); err != nil {
 		transactionRollback(ctx, tx, op)
-		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+		return 0, wrapError(op+": failed to commit transaction", err)
+	}
+
+	return id, nil
+}
+
+// insertSong adds the song together with its artist within the transaction.
+func insertSong(ctx context.Context, tx pgx.Tx, songDTO *models.SongDTO) (int64, error) {
+	artistId, err := upsertArtist(ctx, tx, songDTO.GroupName)
+	if err != nil {
+		return 0, err
+	}
+
+	const query = `INSERT INTO songs(artist_id, song_name, release_date, song_text, link) 
+                   VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	var id int64
+	err = tx.QueryRow(ctx, query, artistId, songDTO.SongName, nullableDate(songDTO.ReleaseDate),
+		songDTO.Text, songDTO.Link).Scan(&id)
+	if err != nil {
+		return 0, err
 	}
 
 	return id, nil
 }
 
-func (s *Storage) GetAllSongs(ctx context.Context) ([]models.SongDTO, error) {
-	const op = "storage/postgres.GetAllSongs"
+// GetSongs returns the page of songs matching the where clause in the given order and reports whether
+// there are more songs after the page (before the page for backward cursors).
+// Only the requested fields and the sort keys are selected, the rest of the song fields stay empty.
+func (s *Storage) GetSongs(ctx context.Context, where *models.WhereBuilder, orders []models.Order,
+	fields []models.Column, page models.Page) ([]models.SongDTO, bool, error) {
+
+	const op = "storage/postgres.GetSongs"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, group_name, song_name, release_date, song_text, link FROM songs`
+	if len(orders) == 0 {
+		orders = models.DefaultSort
+	}
+	if len(fields) == 0 {
+		fields = models.AllFields
+	}
+
+	columns := selectedColumns(fields, orders)
+
+	var args models.Args
+	query := `SELECT ` + joinColumns(columns) + ` FROM ` + songsSource + " "
+	if cursor := page.Cursor; cursor != nil {
+		keyset := models.After(orders, cursor.Id, cursor.Values, cursor.Backward)
+		query += models.Where(where, keyset).Build(&args) + " " + models.OrderBy(orders, cursor.Backward)
+	} else {
+		query += where.Build(&args) + " " + models.OrderBy(orders, false)
+		query += " OFFSET " + args.Add((page.Number-1)*page.Limit)
+	}
+	// one extra row tells whether there is something after the page
+	query += " LIMIT " + args.Add(page.Limit+1)
 
-	rows, err := s.dbPool.Query(ctx, query)
+	rows, err := s.dbPool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, false, wrapError(op, err)
 	}
 	defer rows.Close()
-	var songs []models.SongDTO
+
+	songs := make([]models.SongDTO, 0)
 	for rows.Next() {
 		var song models.SongDTO
-		if err = rows.Scan(&song.Id, &song.GroupName, &song.SongName, &song.ReleaseDate, &song.Text, &song.Link); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+		if err = scanColumns(rows, &song, columns); err != nil {
+			return nil, false, wrapError(op, err)
 		}
 		songs = append(songs, song)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, false, wrapError(op, err)
+	}
 
-	return songs, nil
+	hasMore := len(songs) > page.Limit
+	if hasMore {
+		songs = songs[:page.Limit]
+	}
+	if page.Cursor != nil && page.Cursor.Backward {
+		slices.Reverse(songs)
+	}
+
+	return songs, hasMore, nil
 }
 
-func (s *Storage) GetSongs(ctx context.Context, songDTO *models.SongDTO, page int, limit int,
-	opts ...models.OptionFunc) ([]models.SongDTO, error) {
+// GetSong returns the song by id or storage.ErrSongNotFound.
+func (s *Storage) GetSong(ctx context.Context, id int64) (*models.SongDTO, error) {
+	const op = "storage/postgres.GetSong"
 
-	const op = "storage/postgres.GetSongs"
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + songColumns + ` FROM ` + songsSource + ` WHERE id=$1`
+
+	var song models.SongDTO
+	err := scanSong(s.dbPool.QueryRow(ctx, query, id), &song)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrSongNotFound
+	}
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return &song, nil
+}
+
+// FindSong returns id of the song with the given name of the given artist, names are compared case-insensitively.
+func (s *Storage) FindSong(ctx context.Context, groupName string, songName string) (int64, error) {
+	const op = "storage/postgres.FindSong"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	offset := (page - 1) * limit
+	const query = `SELECT songs.id FROM songs JOIN artists ON artists.id = songs.artist_id
+                   WHERE artists.normalized_name=lower(btrim($1)) AND lower(btrim(songs.song_name))=lower(btrim($2))`
 
-	query := `SELECT id, group_name, song_name, release_date, song_text, link FROM songs `
-	whereClause, args := models.BuildQuery(whereOP, songDTO, opts...)
-	query += whereClause
-	query += fmt.Sprintf(" ORDER BY id OFFSET $%d LIMIT $%d", len(args)+1, len(args)+2)
-	args = append(args, offset, limit)
+	var id int64
+	err := s.dbPool.QueryRow(ctx, query, groupName, songName).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, storage.ErrSongNotFound
+	}
+	if err != nil {
+		return 0, wrapError(op, err)
+	}
+
+	return id, nil
+}
+
+// ExportSongs calls fn for every song matching the where clause in the given order.
+// Rows are scanned one by one as they arrive from the connection, so memory usage does not depend
+// on the size of the catalog. The export is not limited in time, ctx should be cancelled by the caller.
+func (s *Storage) ExportSongs(ctx context.Context, where *models.WhereBuilder, orders []models.Order,
+	fields []models.Column, fn func(song *models.SongDTO) error) error {
+
+	const op = "storage/postgres.ExportSongs"
+
+	if len(orders) == 0 {
+		orders = models.DefaultSort
+	}
+	if len(fields) == 0 {
+		fields = models.AllFields
+	}
+
+	columns := selectedColumns(fields, orders)
+
+	var args models.Args
+	query := `SELECT ` + joinColumns(columns) + ` FROM ` + songsSource + " " +
+		where.Build(&args) + " " + models.OrderBy(orders, false)
 
 	rows, err := s.dbPool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return wrapError(op, err)
 	}
 	defer rows.Close()
 
-	songs := make([]models.SongDTO, 0)
 	for rows.Next() {
 		var song models.SongDTO
-		if err = rows.Scan(&song.Id, &song.GroupName, &song.SongName, &song.ReleaseDate, &song.Text, &song.Link); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+		if err = scanColumns(rows, &song, columns); err != nil {
+			return wrapError(op, err)
 		}
-		songs = append(songs, song)
+		if err = fn(&song); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return wrapError(op, err)
+	}
+
+	return nil
+}
+
+// CountSongs returns the number of songs matching the where clause.
+func (s *Storage) CountSongs(ctx context.Context, where *models.WhereBuilder) (int, error) {
+	const op = "storage/postgres.CountSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var args models.Args
+	query := `SELECT count(*) FROM ` + songsSource + " " + where.Build(&args)
+
+	var count int
+	if err := s.dbPool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, wrapError(op, err)
 	}
 
-	return songs, nil
+	return count, nil
 }
 
 func (s *Storage) GetVerses(ctx context.Context, id int64, verse, limit int) (string, error) {
@@ -132,25 +264,22 @@ func (s *Storage) GetVerses(ctx context.Context, id int64, verse, limit int) (st
 	query := `SELECT song_text FROM songs WHERE id=$1`
 	var songText string
 	err := s.dbPool.QueryRow(ctx, query, id).Scan(&songText)
-	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrSongNotFound
 	}
-
-	verses := strings.Split(songText, "\n\n")
-
-	start := (verse - 1) * limit
-	end := start + limit
-	if start >= len(verses) {
-		start = 0
-	}
-	if end > len(verses) {
-		end = 1
+	if err != nil {
+		return "", wrapError(op, err)
 	}
 
-	return strings.Join(verses[start:end], "\n\n"), nil
+	return models.Verses(songText, verse, limit), nil
 }
 
-func (s *Storage) UpdateSong(ctx context.Context, songDTO *models.SongDTO, opts ...models.OptionFunc) error {
+// UpdateSong applies the assignments to the song and returns the song as it is after the update.
+// Assignments of nil clear nullable columns, a song without release date inherits it from its album.
+// The update is applied only if the song is still of the given version, version 0 skips the check.
+func (s *Storage) UpdateSong(ctx context.Context, id int64, version int64,
+	set *models.SetBuilder) (*models.SongDTO, error) {
+
 	const op = "storage/postgres.UpdateSong"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -158,29 +287,61 @@ func (s *Storage) UpdateSong(ctx context.Context, songDTO *models.SongDTO, opts
 
 	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, wrapError(op, err)
+	}
+
+	if groupName, ok := set.Lookup(models.ColumnGroupName); ok {
+		if _, err = upsertArtist(ctx, tx, fmt.Sprint(groupName)); err != nil {
+			transactionRollback(ctx, tx, op)
+			return nil, wrapError(op, err)
+		}
 	}
 
-	query := `UPDATE songs `
-	setClause, args := models.BuildQuery(setOP, songDTO, opts...)
-	query += setClause
-	query += fmt.Sprintf(" WHERE id=$%d", len(args)+1)
-	args = append(args, songDTO.Id)
+	updated := false
+	if !set.Empty() {
+		var args models.Args
+		query := `UPDATE songs ` + set.Build(&args) + `, updated_at=now(), version=version+1
+                  WHERE id=` + args.Add(id)
+		if version != 0 {
+			query += ` AND version=` + args.Add(version)
+		}
+
+		res, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			transactionRollback(ctx, tx, op)
+			return nil, wrapError(op, err)
+		}
+		updated = res.RowsAffected() != 0
+	}
 
-	_, err = tx.Exec(ctx, query, args...)
+	query := `SELECT ` + songColumns + ` FROM ` + songsSource + ` WHERE id=$1`
+
+	var song models.SongDTO
+	err = scanSong(tx.QueryRow(ctx, query, id), &song)
+	if errors.Is(err, pgx.ErrNoRows) {
+		transactionRollback(ctx, tx, op)
+		return nil, storage.ErrSongNotFound
+	}
 	if err != nil {
 		transactionRollback(ctx, tx, op)
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, wrapError(op, err)
+	}
+	// the song exists, so it was not updated because of another version
+	if version != 0 && !updated && (!set.Empty() || song.Version != version) {
+		transactionRollback(ctx, tx, op)
+		return nil, storage.ErrVersionMismatch
 	}
+
 	if err = tx.Commit(ctx); err != nil {
 		transactionRollback(ctx, tx, op)
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, wrapError(op, err)
 	}
 
-	return nil
+	return &song, nil
 }
 
-func (s *Storage) DeleteSong(ctx context.Context, id int64) error {
+// DeleteSong deletes the song if it is still of the given version, version 0 skips the check.
+func (s *Storage) DeleteSong(ctx context.Context, id int64, version int64) error {
 	const op = "storage/postgres.DeleteSong"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -188,31 +349,130 @@ func (s *Storage) DeleteSong(ctx context.Context, id int64) error {
 
 	tx, err := s.dbPool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return wrapError(op, err)
+	}
+
+	var args models.Args
+	query := `DELETE FROM songs WHERE id=` + args.Add(id)
+	if version != 0 {
+		query += ` AND version=` + args.Add(version)
 	}
 
-	res, err := tx.Exec(ctx, "DELETE FROM songs WHERE id = $1;", id)
+	res, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		transactionRollback(ctx, tx, op)
-		return fmt.Errorf("%s: %w", op, err)
+		return wrapError(op, err)
 	}
 	if res.RowsAffected() == 0 {
 		transactionRollback(ctx, tx, op)
-		return storage.ErrNoAffected
+		if version == 0 {
+			return storage.ErrSongNotFound
+		}
+		return s.versionMismatch(ctx, op, id)
 	}
 
 	if err = tx.Commit(ctx); err != nil {
 		transactionRollback(ctx, tx, op)
-		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+		return wrapError(op+": failed to commit transaction", err)
 	}
 
 	return nil
 }
 
-func transactionRollback(ctx context.Context, tx pgx.Tx, op string) {
-	err := tx.Rollback(ctx)
+// versionMismatch tells why a conditional write of the song affected no rows.
+func (s *Storage) versionMismatch(ctx context.Context, op string, id int64) error {
+	var exists bool
+	err := s.dbPool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM songs WHERE id=$1)`, id).Scan(&exists)
 	if err != nil {
-		panicMessage := fmt.Sprintf("%s: %s", op, err.Error())
-		panic(panicMessage)
+		return wrapError(op, err)
+	}
+	if !exists {
+		return storage.ErrSongNotFound
+	}
+	return storage.ErrVersionMismatch
+}
+
+// scanSong scans a row selected with songColumns into the song,
+// columns selected after songColumns are scanned into extra destinations.
+func scanSong(row pgx.Row, song *models.SongDTO, extra ...any) error {
+	var releaseDate *time.Time
+	dest := append([]any{&song.Id, &song.GroupName, &song.SongName, &releaseDate, &song.Text, &song.Link,
+		&song.UpdatedAt, &song.Version}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if releaseDate != nil {
+		song.ReleaseDate = *releaseDate
+	}
+
+	return nil
+}
+
+// selectedColumns returns fields followed by the sort keys needed to build cursors, without duplicates.
+func selectedColumns(fields []models.Column, orders []models.Order) []models.Column {
+	columns := slices.Clone(fields)
+	for _, order := range orders {
+		if !slices.Contains(columns, order.Column) {
+			columns = append(columns, order.Column)
+		}
+	}
+	return columns
+}
+
+func joinColumns(columns []models.Column) string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, string(column))
+	}
+	return strings.Join(names, ", ")
+}
+
+// scanColumns scans a row selected with the given columns into the song.
+func scanColumns(row pgx.Row, song *models.SongDTO, columns []models.Column) error {
+	var releaseDate *time.Time
+	dest := make([]any, 0, len(columns))
+	for _, column := range columns {
+		switch column {
+		case models.ColumnId:
+			dest = append(dest, &song.Id)
+		case models.ColumnGroupName:
+			dest = append(dest, &song.GroupName)
+		case models.ColumnSongName:
+			dest = append(dest, &song.SongName)
+		case models.ColumnReleaseDate:
+			dest = append(dest, &releaseDate)
+		case models.ColumnText:
+			dest = append(dest, &song.Text)
+		case models.ColumnLink:
+			dest = append(dest, &song.Link)
+		}
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if releaseDate != nil {
+		song.ReleaseDate = *releaseDate
+	}
+
+	return nil
+}
+
+// nullableDate converts zero date to NULL, so that the song may inherit release date from its album.
+func nullableDate(date time.Time) *time.Time {
+	if date.IsZero() {
+		return nil
+	}
+	return &date
+}
+
+// transactionRollback rolls the transaction back also when ctx is done, which is often the reason of the rollback.
+// A failed rollback is only logged, pgx closes the connection then and the server aborts the transaction itself.
+func transactionRollback(ctx context.Context, tx pgx.Tx, op string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		slog.Default().Error("failed to rollback transaction", slog.String("op", op), slog.String("error", err.Error()))
 	}
 }
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"testing"
+	"time"
+)
+
+// rollbackTx records the context of the rollback, other methods of pgx.Tx are not used.
+type rollbackTx struct {
+	pgx.Tx
+	ctxErr error
+	err    error
+}
+
+func (tx *rollbackTx) Rollback(ctx context.Context) error {
+	tx.ctxErr = ctx.Err()
+	return tx.err
+}
+
+func TestTransactionRollbackAfterTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	tx := &rollbackTx{}
+	transactionRollback(ctx, tx, "test")
+
+	if tx.ctxErr != nil {
+		t.Errorf("rollback ran with done context: %v", tx.ctxErr)
+	}
+}
+
+func TestTransactionRollbackFailureDoesNotPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("failed rollback panicked: %v", r)
+		}
+	}()
+
+	transactionRollback(context.Background(), &rollbackTx{err: errors.New("conn closed")}, "test")
+	transactionRollback(context.Background(), &rollbackTx{err: pgx.ErrTxClosed}, "test")
+}
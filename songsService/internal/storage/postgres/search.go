@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"rest/pkg/models"
+	"time"
+)
+
+// searchConfig is text search configuration used both for the songs search_vector column and for queries,
+// "simple" is language agnostic, so lyrics in any language are searchable.
+const searchConfig = "simple"
+
+const headlineOptions = "StartSel=<b>, StopSel=</b>, MaxFragments=3, FragmentDelimiter=\" ... \""
+
+// SearchSongs finds songs by lyrics and song name ordered by relevance,
+// every result contains a snippet of the lyrics with highlighted matches.
+func (s *Storage) SearchSongs(ctx context.Context, q string, page int, limit int) ([]models.SearchResultDTO, error) {
+	const op = "storage/postgres.SearchSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	offset := (page - 1) * limit
+
+	// snippets are built only for the requested page, ts_headline is too expensive to run on every match
+	query := `SELECT ` + songColumns + `, rank, ts_headline('` + searchConfig + `', song_text, query, $4)
+              FROM (SELECT ` + songColumns + `, query, ts_rank(search_vector, query) AS rank
+                    FROM ` + songsSource + `, websearch_to_tsquery('` + searchConfig + `', $1) AS query
+                    WHERE search_vector @@ query
+                    ORDER BY rank DESC, id
+                    OFFSET $2 LIMIT $3) AS matches
+              ORDER BY rank DESC, id`
+
+	rows, err := s.dbPool.Query(ctx, query, q, offset, limit, headlineOptions)
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	defer rows.Close()
+
+	results := make([]models.SearchResultDTO, 0)
+	for rows.Next() {
+		var result models.SearchResultDTO
+		if err = scanSong(rows, &result.Song, &result.Rank, &result.Snippet); err != nil {
+			return nil, wrapError(op, err)
+		}
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return results, nil
+}
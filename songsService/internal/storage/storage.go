@@ -2,6 +2,47 @@ package storage
 
 import "errors"
 
+// Kinds of storage errors. Every error returned by storages wraps at most one kind,
+// handlers choose the status of the response by the kind.
+// ErrPrecondition is returned by conditional writes when the stored version differs from the expected one.
 var (
-	ErrNoAffected = errors.New("no rows were affected")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrConstraint   = errors.New("constraint violation")
+	ErrTimeout      = errors.New("storage timeout")
+	ErrPrecondition = errors.New("precondition failed")
 )
+
+var (
+	ErrArtistNotFound  = &Error{Kind: ErrNotFound, Msg: "artist not found"}
+	ErrArtistExists    = &Error{Kind: ErrConflict, Msg: "artist with such name already exists"}
+	ErrAlbumNotFound   = &Error{Kind: ErrNotFound, Msg: "album not found"}
+	ErrSongNotFound    = &Error{Kind: ErrNotFound, Msg: "song not found"}
+	ErrSongExists      = &Error{Kind: ErrConflict, Msg: "song with such name already exists"}
+	ErrTracksMismatch  = &Error{Kind: ErrConstraint, Msg: "tracks do not match songs of the album"}
+	ErrVersionMismatch = &Error{Kind: ErrPrecondition, Msg: "song was modified, fetch the current version and retry"}
+	ErrJobNotFound     = &Error{Kind: ErrNotFound, Msg: "job not found"}
+	ErrJobLeaseLost    = &Error{Kind: ErrConflict, Msg: "job lease expired and the job was taken over"}
+)
+
+// Error is a storage error of a certain kind.
+// Msg is safe to show to clients, Err is the underlying driver error if any and is only logged.
+type Error struct {
+	Kind error
+	Msg  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Msg + ": " + e.Err.Error()
+	}
+	return e.Msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS jobs
+(
+    id BIGSERIAL PRIMARY KEY,
+    song_id BIGINT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- workers poll only for jobs that are due, running jobs are polled too to take over expired leases
+CREATE INDEX IF NOT EXISTS jobs_run_at_idx ON jobs(run_at) WHERE status IN ('pending', 'running');
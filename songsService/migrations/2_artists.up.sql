@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS artists
+(
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    normalized_name VARCHAR(255) GENERATED ALWAYS AS (lower(btrim(name))) STORED UNIQUE
+);
+
+INSERT INTO artists(name)
+SELECT DISTINCT ON (lower(btrim(group_name))) btrim(group_name)
+FROM songs
+ORDER BY lower(btrim(group_name)), id;
+
+ALTER TABLE songs ADD COLUMN artist_id BIGINT REFERENCES artists(id);
+
+UPDATE songs
+SET artist_id = artists.id
+FROM artists
+WHERE artists.normalized_name = lower(btrim(songs.group_name));
+
+ALTER TABLE songs ALTER COLUMN artist_id SET NOT NULL;
+ALTER TABLE songs DROP COLUMN group_name;
+
+CREATE INDEX IF NOT EXISTS songs_artist_id_idx ON songs(artist_id);
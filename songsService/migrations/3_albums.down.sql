@@ -0,0 +1,13 @@
+UPDATE songs
+SET release_date = albums.release_date
+FROM albums
+WHERE albums.id = songs.album_id AND songs.release_date IS NULL;
+
+UPDATE songs SET release_date = 'epoch' WHERE release_date IS NULL;
+ALTER TABLE songs ALTER COLUMN release_date SET NOT NULL;
+
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS songs_album_track_key;
+ALTER TABLE songs DROP COLUMN track_number;
+ALTER TABLE songs DROP COLUMN album_id;
+
+DROP TABLE IF EXISTS albums;
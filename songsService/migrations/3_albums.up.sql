@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS albums
+(
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    artist_id BIGINT NOT NULL REFERENCES artists(id),
+    release_date DATE,
+    cover_link TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS albums_artist_id_idx ON albums(artist_id);
+
+ALTER TABLE songs ADD COLUMN album_id BIGINT REFERENCES albums(id) ON DELETE SET NULL;
+ALTER TABLE songs ADD COLUMN track_number INT CHECK (track_number > 0);
+ALTER TABLE songs ADD CONSTRAINT songs_album_track_key UNIQUE (album_id, track_number) DEFERRABLE INITIALLY IMMEDIATE;
+
+-- songs without their own release date inherit it from the album
+ALTER TABLE songs ALTER COLUMN release_date DROP NOT NULL;
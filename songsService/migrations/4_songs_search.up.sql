@@ -0,0 +1,7 @@
+ALTER TABLE songs ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', song_name), 'A') ||
+        setweight(to_tsvector('simple', song_text), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS songs_search_vector_idx ON songs USING GIN (search_vector);
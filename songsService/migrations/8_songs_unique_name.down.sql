@@ -0,0 +1 @@
+DROP INDEX IF EXISTS songs_artist_id_song_name_key;
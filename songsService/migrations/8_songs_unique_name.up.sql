@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS songs_artist_id_song_name_key;
+
+-- names which differ only in case or surrounding spaces are duplicates now, the oldest song keeps its name
+-- and the others get their id appended, so no song is lost and the duplicates can be merged by hand
+WITH duplicates AS (
+    SELECT id, ' (' || id || ')' AS suffix
+    FROM (SELECT id, row_number() OVER (PARTITION BY artist_id, lower(btrim(song_name)) ORDER BY id) AS n
+          FROM songs) numbered
+    WHERE n > 1
+)
+UPDATE songs
+SET song_name = left(btrim(song_name), 255 - length(duplicates.suffix)) || duplicates.suffix,
+    updated_at = now(),
+    version = version + 1
+FROM duplicates
+WHERE duplicates.id = songs.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS songs_artist_id_song_name_key ON songs (artist_id, lower(btrim(song_name)));
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidFields = errors.New("invalid fields parameter")
+
+// fieldColumns maps names of song fields used in the API to columns.
+var fieldColumns = map[string]Column{
+	"id":           ColumnId,
+	"group_name":   ColumnGroupName,
+	"song_name":    ColumnSongName,
+	"release_date": ColumnReleaseDate,
+	"text":         ColumnText,
+	"link":         ColumnLink,
+}
+
+// AllFields are all fields of a song.
+var AllFields = []Column{ColumnId, ColumnGroupName, ColumnSongName, ColumnReleaseDate, ColumnText, ColumnLink}
+
+// DefaultFields are fields returned by list calls, lyrics are by far the largest column and are omitted.
+var DefaultFields = []Column{ColumnId, ColumnGroupName, ColumnSongName, ColumnReleaseDate, ColumnLink}
+
+// ParseFields parses comma-separated list of field names like "id,group_name,song_name".
+func ParseFields(fields string) ([]Column, error) {
+	if fields == "" {
+		return DefaultFields, nil
+	}
+
+	columns := make([]Column, 0, len(fieldColumns))
+	seen := make(map[Column]bool, len(fieldColumns))
+	for _, field := range strings.Split(fields, ",") {
+		column, ok := fieldColumns[strings.TrimSpace(field)]
+		if !ok {
+			return nil, ErrInvalidFields
+		}
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	return columns, nil
+}
+
+// ParseField returns the column of the song field with the given API name.
+func ParseField(field string) (Column, error) {
+	column, ok := fieldColumns[field]
+	if !ok {
+		return "", ErrInvalidFields
+	}
+	return column, nil
+}
+
+// FieldName returns name of the field in the API.
+func FieldName(column Column) string {
+	if column == ColumnText {
+		return "text"
+	}
+	return string(column)
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type SongDTO struct {
 	Id          int64
@@ -9,8 +12,85 @@ type SongDTO struct {
 	Text        string
 	ReleaseDate time.Time
 	Link        string
+	UpdatedAt   time.Time
+	Version     int64
 }
 
-func checkEmpty(s SongDTO) bool {
-	return s.Id == 0 && s.GroupName == "" && s.SongName == "" && s.Text == "" && s.Link == "" && s.ReleaseDate.IsZero()
+type ArtistDTO struct {
+	Id    int64
+	Name  string
+	Songs []SongDTO
+}
+
+type AlbumDTO struct {
+	Id          int64
+	Title       string
+	GroupName   string
+	ReleaseDate time.Time
+	CoverLink   string
+	Tracks      []TrackDTO
+}
+
+type TrackDTO struct {
+	Number int
+	Song   SongDTO
+}
+
+type SearchResultDTO struct {
+	Song    SongDTO
+	Rank    float32
+	Snippet string
+}
+
+// IdempotencyRecordDTO is the response stored for an idempotency key, Status is 0 while the request is in progress.
+type IdempotencyRecordDTO struct {
+	RequestHash string
+	Status      int
+	Headers     map[string][]string
+	Response    []byte
+}
+
+// ImportRowDTO is a song read from the line of an imported file.
+type ImportRowDTO struct {
+	Line int
+	Song SongDTO
+}
+
+// Statuses of background jobs.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// JobDTO is a background enrichment of the song with details from the external API.
+type JobDTO struct {
+	Id        int64
+	SongId    int64
+	Status    string
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Verses returns the page of limit verses with the given number, verses of the text are separated by blank lines.
+// The last page may be shorter, pages after it are empty.
+func Verses(text string, verse, limit int) string {
+	if verse < 1 || limit < 1 {
+		return ""
+	}
+
+	verses := strings.Split(text, "\n\n")
+
+	// compared by pages, so large verse and limit do not overflow
+	if pages := (len(verses)-1)/limit + 1; verse > pages {
+		return ""
+	}
+
+	start := (verse - 1) * limit
+	end := start + min(limit, len(verses)-start)
+
+	return strings.Join(verses[start:end], "\n\n")
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVerses(t *testing.T) {
+	const text = "first\n\nsecond\n\nthird"
+
+	tests := []struct {
+		name         string
+		verse, limit int
+		want         string
+	}{
+		{"first verse", 1, 1, "first"},
+		{"last verse", 3, 1, "third"},
+		{"first page", 1, 2, "first\n\nsecond"},
+		{"short last page", 2, 2, "third"},
+		{"whole text", 1, 3, text},
+		{"limit above verses count", 1, 10, text},
+		{"verse after the end", 4, 1, ""},
+		{"page after the end", 3, 2, ""},
+		{"max limit", 1, math.MaxInt, text},
+		{"max verse", math.MaxInt, 1, ""},
+		{"max verse and limit", math.MaxInt, math.MaxInt, ""},
+		{"zero verse", 0, 1, ""},
+		{"zero limit", 1, 0, ""},
+		{"negative limit", 1, -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verses(text, tt.verse, tt.limit); got != tt.want {
+				t.Errorf("Verses(%d, %d) = %q, want %q", tt.verse, tt.limit, got, tt.want)
+			}
+		})
+	}
+
+	if got := Verses("", 1, 1); got != "" {
+		t.Errorf("Verses of empty text = %q, want empty", got)
+	}
+	if got := Verses("single", 1, 2); got != "single" {
+		t.Errorf("Verses of single verse = %q, want single", got)
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxLimit is the largest page size clients may request.
+const MaxLimit = 100
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidPage   = fmt.Errorf("page and limit must be greater than 0, limit must not be greater than %d", MaxLimit)
+)
+
+// Page describes the requested part of a list.
+// Lists are paginated either by page number (offset pagination) or by cursor (keyset pagination),
+// cursor takes precedence over page number.
+type Page struct {
+	Number int
+	Limit  int
+	Cursor *Cursor
+}
+
+// ValidatePage checks page number and size received from the client,
+// so the page is never larger than MaxLimit and its offset always fits in int.
+func ValidatePage(number int, limit int) error {
+	if number < 1 || limit < 1 || limit > MaxLimit || number > math.MaxInt/MaxLimit {
+		return ErrInvalidPage
+	}
+	return nil
+}
+
+// Cursor points to the row the page starts after (or ends before, for backward cursors).
+// Besides id it keeps values of the other sort keys of the row and the sort it was issued for.
+// Clients receive it as an opaque token and must not rely on its content.
+type Cursor struct {
+	Id       int64    `json:"id"`
+	Values   []string `json:"v,omitempty"`
+	Sort     string   `json:"s,omitempty"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// NewCursor returns cursor pointing to the song in the given order.
+func NewCursor(song *SongDTO, orders []Order, backward bool) *Cursor {
+	cursor := &Cursor{Id: song.Id, Sort: FormatSort(orders), Backward: backward}
+	for _, order := range orders {
+		if order.Column != ColumnId {
+			cursor.Values = append(cursor.Values, SortValue(song, order.Column))
+		}
+	}
+	return cursor
+}
+
+// Matches reports whether the cursor was issued for the given order and its values are of the types
+// of their sort columns, so a tampered cursor is rejected before it gets into the query.
+func (c *Cursor) Matches(orders []Order) bool {
+	if c.Sort != FormatSort(orders) || len(c.Values) != len(orders)-1 {
+		return false
+	}
+
+	values := c.Values
+	for _, order := range orders {
+		if order.Column == ColumnId {
+			continue
+		}
+		if !validSortValue(order.Column, values[0]) {
+			return false
+		}
+		values = values[1:]
+	}
+	return true
+}
+
+// validSortValue reports whether the value may be compared with the column, it is the inverse of SortValue.
+func validSortValue(column Column, value string) bool {
+	switch column {
+	case ColumnReleaseDate:
+		if value == negativeInfinity {
+			return true
+		}
+		_, err := time.Parse(dateLayout, value)
+		return err == nil
+	default:
+		// text columns take any string Postgres can store
+		return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	}
+}
+
+// Encode returns opaque token of the cursor.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses token received from the client.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValidatePage(t *testing.T) {
+	tests := []struct {
+		name   string
+		number int
+		limit  int
+		valid  bool
+	}{
+		{"first page", 1, 10, true},
+		{"max limit", 1, MaxLimit, true},
+		{"last page with offset in range", math.MaxInt / MaxLimit, MaxLimit, true},
+		{"zero page", 0, 10, false},
+		{"negative page", -1, 10, false},
+		{"zero limit", 1, 0, false},
+		{"negative limit", 1, -5, false},
+		{"limit above max", 1, MaxLimit + 1, false},
+		{"huge limit", 1, 1_000_000_000, false},
+		{"max int limit", 1, math.MaxInt, false},
+		{"offset overflow", math.MaxInt/MaxLimit + 1, 1, false},
+		{"max int page", math.MaxInt, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePage(tt.number, tt.limit)
+			if tt.valid && err != nil {
+				t.Errorf("ValidatePage(%d, %d) = %v, want nil", tt.number, tt.limit, err)
+			}
+			if !tt.valid && err != ErrInvalidPage {
+				t.Errorf("ValidatePage(%d, %d) = %v, want ErrInvalidPage", tt.number, tt.limit, err)
+			}
+		})
+	}
+}
+
+func TestCursorMatches(t *testing.T) {
+	orders, err := ParseSort("-release_date,song_name")
+	if err != nil {
+		t.Fatalf("ParseSort: %v", err)
+	}
+	sort := FormatSort(orders)
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+		want   bool
+	}{
+		{"valid", Cursor{Id: 1, Values: []string{"2006-07-16", "Uprising"}, Sort: sort}, true},
+		{"song without release date", Cursor{Id: 1, Values: []string{"-infinity", ""}, Sort: sort}, true},
+		{"another sort", Cursor{Id: 1, Values: []string{"2006-07-16", "Uprising"}, Sort: "id"}, false},
+		{"missing value", Cursor{Id: 1, Values: []string{"2006-07-16"}, Sort: sort}, false},
+		{"extra value", Cursor{Id: 1, Values: []string{"2006-07-16", "a", "b"}, Sort: sort}, false},
+		{"date is not a date", Cursor{Id: 1, Values: []string{"yesterday", "Uprising"}, Sort: sort}, false},
+		{"date in another layout", Cursor{Id: 1, Values: []string{"16.07.2006", "Uprising"}, Sort: sort}, false},
+		{"invalid date", Cursor{Id: 1, Values: []string{"2006-02-30", "Uprising"}, Sort: sort}, false},
+		{"invalid utf-8", Cursor{Id: 1, Values: []string{"2006-07-16", "\xff"}, Sort: sort}, false},
+		{"nul byte", Cursor{Id: 1, Values: []string{"2006-07-16", "a\x00b"}, Sort: sort}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cursor.Matches(orders); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorOfSongMatches(t *testing.T) {
+	orders, _ := ParseSort("group_name,-release_date")
+	for _, song := range []*SongDTO{
+		{Id: 3, GroupName: "Muse", ReleaseDate: time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC)},
+		{Id: 4, GroupName: "Queen"},
+	} {
+		cursor, err := DecodeCursor(NewCursor(song, orders, false).Encode())
+		if err != nil {
+			t.Fatalf("DecodeCursor: %v", err)
+		}
+		if !cursor.Matches(orders) {
+			t.Errorf("cursor of song %d does not match its order: %+v", song.Id, cursor)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Due to the small number of endpoints in this project, it was decided not to include query builders like Squirrel or ORMs like GORM.
+Instead, a custom solution was developed: typed columns, conditions combined into AND/OR groups and separate
+builders for WHERE and SET clauses which share one list of query arguments.
+Values never get into the query text, they are always passed as numbered placeholders, and the only identifiers
+which get into the query text are the Column constants below, so the generated SQL is safe from injections.
+*/
+
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column is a column of the songs table which may be used in queries.
+type Column string
+
+const (
+	ColumnId          Column = "id"
+	ColumnGroupName   Column = "group_name"
+	ColumnSongName    Column = "song_name"
+	ColumnReleaseDate Column = "release_date"
+	ColumnText        Column = "song_text"
+	ColumnLink        Column = "link"
+)
+
+var columns = map[string]Column{
+	string(ColumnId):          ColumnId,
+	string(ColumnGroupName):   ColumnGroupName,
+	string(ColumnSongName):    ColumnSongName,
+	string(ColumnReleaseDate): ColumnReleaseDate,
+	string(ColumnText):        ColumnText,
+	string(ColumnLink):        ColumnLink,
+}
+
+// ParseColumn converts a column name received from the client into Column,
+// names which are not in the whitelist are rejected.
+func ParseColumn(name string) (Column, error) {
+	column, ok := columns[name]
+	if !ok {
+		return "", fmt.Errorf("unknown column %q", name)
+	}
+	return column, nil
+}
+
+// Args is a list of query arguments shared by all clauses of one query.
+type Args []any
+
+// Add appends the value to the arguments and returns its placeholder.
+func (a *Args) Add(value any) string {
+	*a = append(*a, value)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// Condition is a predicate of WHERE clause.
+// Conditions built from empty values (empty string, zero number or date, empty slice) are empty and are skipped,
+// so the result of parsing an optional query parameter can be passed as is.
+type Condition interface {
+	// build returns SQL of the condition or empty string for empty conditions.
+	build(args *Args) string
+}
+
+type operator string
+
+const (
+	opEq      operator = "="
+	opGt      operator = ">"
+	opGte     operator = ">="
+	opLt      operator = "<"
+	opLte     operator = "<="
+	opAny     operator = "= ANY"
+	opILike   operator = "ILIKE"
+	opSimilar operator = "%"
+)
+
+type comparison struct {
+	column   Column
+	operator operator
+	value    any
+}
+
+// Eq matches rows where column is equal to value.
+// Group name is resolved through the artists table, so "Muse" and "muse " point to the same artist.
+func Eq(column Column, value any) Condition {
+	return comparison{column, opEq, value}
+}
+
+// Gt matches rows where column is greater than value.
+func Gt(column Column, value any) Condition {
+	return comparison{column, opGt, value}
+}
+
+// Gte matches rows where column is greater than or equal to value.
+func Gte(column Column, value any) Condition {
+	return comparison{column, opGte, value}
+}
+
+// Lt matches rows where column is less than value.
+func Lt(column Column, value any) Condition {
+	return comparison{column, opLt, value}
+}
+
+// Lte matches rows where column is less than or equal to value.
+func Lte(column Column, value any) Condition {
+	return comparison{column, opLte, value}
+}
+
+// In matches rows where column is equal to any element of values slice.
+func In(column Column, values any) Condition {
+	return comparison{column, opAny, values}
+}
+
+// Prefix is case-insensitive condition matching rows where column starts with prefix.
+func Prefix(column Column, prefix string) Condition {
+	if prefix == "" {
+		return comparison{column, opILike, ""}
+	}
+	return comparison{column, opILike, likeEscaper.Replace(prefix) + "%"}
+}
+
+// Contains is case-insensitive condition matching rows where column contains substr.
+func Contains(column Column, substr string) Condition {
+	if substr == "" {
+		return comparison{column, opILike, ""}
+	}
+	return comparison{column, opILike, "%" + likeEscaper.Replace(substr) + "%"}
+}
+
+// Similar matches rows where column is similar to value by pg_trgm trigram similarity.
+func Similar(column Column, value string) Condition {
+	return comparison{column, opSimilar, value}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (c comparison) build(args *Args) string {
+	if isEmpty(c.value) {
+		return ""
+	}
+
+	placeholder := args.Add(c.value)
+	if c.column == ColumnGroupName {
+		if c.operator == opEq {
+			return "artist_id=(SELECT id FROM artists WHERE normalized_name=lower(btrim(" + placeholder + ")))"
+		}
+		return "artist_id IN (SELECT id FROM artists WHERE " + predicate("name", c.operator, placeholder) + ")"
+	}
+
+	return predicate(string(c.column), c.operator, placeholder)
+}
+
+func predicate(column string, op operator, placeholder string) string {
+	switch op {
+	case opAny:
+		return column + " = ANY(" + placeholder + ")"
+	case opILike, opSimilar:
+		return column + " " + string(op) + " " + placeholder
+	default:
+		return column + string(op) + placeholder
+	}
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	case int64:
+		return v == 0
+	case time.Time:
+		return v.IsZero()
+	}
+
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Slice && rv.Len() == 0
+}
+
+type group struct {
+	separator  string
+	conditions []Condition
+}
+
+// And matches rows satisfying all of the conditions, empty conditions are skipped.
+func And(conditions ...Condition) Condition {
+	return group{" AND ", conditions}
+}
+
+// Or matches rows satisfying any of the conditions, empty conditions are skipped.
+func Or(conditions ...Condition) Condition {
+	return group{" OR ", conditions}
+}
+
+func (g group) build(args *Args) string {
+	parts := make([]string, 0, len(g.conditions))
+	for _, condition := range g.conditions {
+		if part := condition.build(args); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return "(" + strings.Join(parts, g.separator) + ")"
+	}
+}
+
+// WhereBuilder builds WHERE clause joining its conditions with AND.
+type WhereBuilder struct {
+	conditions []Condition
+}
+
+// Where returns WhereBuilder with the given conditions.
+func Where(conditions ...Condition) *WhereBuilder {
+	return &WhereBuilder{conditions: conditions}
+}
+
+// And adds conditions to the clause.
+func (w *WhereBuilder) And(conditions ...Condition) *WhereBuilder {
+	w.conditions = append(w.conditions, conditions...)
+	return w
+}
+
+// Build returns WHERE clause or empty string when all conditions are empty.
+func (w *WhereBuilder) Build(args *Args) string {
+	if parts := w.parts(args); len(parts) != 0 {
+		return "WHERE " + strings.Join(parts, " AND ")
+	}
+	return ""
+}
+
+// build lets WhereBuilder be used as a condition of another builder.
+func (w *WhereBuilder) build(args *Args) string {
+	if w == nil {
+		return ""
+	}
+	return And(w.conditions...).build(args)
+}
+
+func (w *WhereBuilder) parts(args *Args) []string {
+	if w == nil {
+		return nil
+	}
+
+	parts := make([]string, 0, len(w.conditions))
+	for _, condition := range w.conditions {
+		if part := condition.build(args); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
+
+type assignment struct {
+	column Column
+	value  any
+}
+
+// SetBuilder builds SET clause of update-queries.
+// Unlike conditions, assignments are never skipped, so empty values may be used to clear columns.
+type SetBuilder struct {
+	assignments []assignment
+}
+
+// Set returns empty SetBuilder.
+func Set() *SetBuilder {
+	return &SetBuilder{}
+}
+
+// Value assigns value to column, repeated assignment to the same column replaces the previous one.
+// Group name is assigned through the artists table, the artist must exist before the query is executed.
+func (s *SetBuilder) Value(column Column, value any) *SetBuilder {
+	for i := range s.assignments {
+		if s.assignments[i].column == column {
+			s.assignments[i].value = value
+			return s
+		}
+	}
+	s.assignments = append(s.assignments, assignment{column, value})
+	return s
+}
+
+// Lookup returns the value assigned to column.
+func (s *SetBuilder) Lookup(column Column) (any, bool) {
+	for _, a := range s.assignments {
+		if a.column == column {
+			return a.value, true
+		}
+	}
+	return nil, false
+}
+
+// Empty reports whether there are no assignments.
+func (s *SetBuilder) Empty() bool {
+	return len(s.assignments) == 0
+}
+
+// Build returns SET clause or empty string when there are no assignments.
+func (s *SetBuilder) Build(args *Args) string {
+	if s.Empty() {
+		return ""
+	}
+
+	parts := make([]string, 0, len(s.assignments))
+	for _, a := range s.assignments {
+		placeholder := args.Add(a.value)
+		if a.column == ColumnGroupName {
+			parts = append(parts, "artist_id=(SELECT id FROM artists WHERE normalized_name=lower(btrim("+placeholder+")))")
+			continue
+		}
+		parts = append(parts, string(a.column)+"="+placeholder)
+	}
+
+	return "SET " + strings.Join(parts, ", ")
+}
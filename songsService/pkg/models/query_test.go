@@ -0,0 +1,347 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWhereBuilder(t *testing.T) {
+	date := time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		where *WhereBuilder
+		sql   string
+		args  Args
+	}{
+		{
+			name:  "no conditions",
+			where: Where(),
+			sql:   "",
+			args:  nil,
+		},
+		{
+			name:  "nil builder",
+			where: nil,
+			sql:   "",
+			args:  nil,
+		},
+		{
+			name:  "empty conditions are skipped",
+			where: Where(Eq(ColumnSongName, ""), Gt(ColumnId, 0), Lt(ColumnReleaseDate, time.Time{}), In(ColumnId, []int64{})),
+			sql:   "",
+			args:  nil,
+		},
+		{
+			name:  "single condition",
+			where: Where(Eq(ColumnSongName, "Supermassive Black Hole")),
+			sql:   "WHERE song_name=$1",
+			args:  Args{"Supermassive Black Hole"},
+		},
+		{
+			name:  "comparison operators",
+			where: Where(Gt(ColumnId, int64(1)), Gte(ColumnId, int64(2)), Lt(ColumnReleaseDate, date), Lte(ColumnId, 4)),
+			sql:   "WHERE id>$1 AND id>=$2 AND release_date<$3 AND id<=$4",
+			args:  Args{int64(1), int64(2), date, 4},
+		},
+		{
+			name:  "empty condition between others",
+			where: Where(Eq(ColumnSongName, "Uprising"), Eq(ColumnLink, ""), Eq(ColumnText, "text")),
+			sql:   "WHERE song_name=$1 AND song_text=$2",
+			args:  Args{"Uprising", "text"},
+		},
+		{
+			name:  "and adds conditions",
+			where: Where(Eq(ColumnSongName, "Uprising")).And(Gt(ColumnId, 10), Eq(ColumnLink, "")),
+			sql:   "WHERE song_name=$1 AND id>$2",
+			args:  Args{"Uprising", 10},
+		},
+		{
+			name:  "or group",
+			where: Where(Or(Eq(ColumnSongName, "a"), Eq(ColumnSongName, "b"))),
+			sql:   "WHERE (song_name=$1 OR song_name=$2)",
+			args:  Args{"a", "b"},
+		},
+		{
+			name:  "or group with single non-empty condition is not wrapped",
+			where: Where(Or(Eq(ColumnSongName, ""), Eq(ColumnSongName, "b"))),
+			sql:   "WHERE song_name=$1",
+			args:  Args{"b"},
+		},
+		{
+			name:  "or group with empty conditions only is skipped",
+			where: Where(Or(Eq(ColumnSongName, ""), Eq(ColumnLink, "")), Gt(ColumnId, 1)),
+			sql:   "WHERE id>$1",
+			args:  Args{1},
+		},
+		{
+			name: "nested groups",
+			where: Where(
+				Or(
+					And(Eq(ColumnSongName, "a"), Gt(ColumnId, 1)),
+					And(Eq(ColumnSongName, "b"), Eq(ColumnLink, "")),
+				),
+				Lte(ColumnId, 100),
+			),
+			sql:  "WHERE ((song_name=$1 AND id>$2) OR song_name=$3) AND id<=$4",
+			args: Args{"a", 1, "b", 100},
+		},
+		{
+			name:  "where builder as condition",
+			where: Where(Or(Where(Eq(ColumnSongName, "a"), Gt(ColumnId, 1)), Eq(ColumnSongName, "b"))),
+			sql:   "WHERE ((song_name=$1 AND id>$2) OR song_name=$3)",
+			args:  Args{"a", 1, "b"},
+		},
+		{
+			name:  "nil where builder as condition",
+			where: Where((*WhereBuilder)(nil), Gt(ColumnId, 1)),
+			sql:   "WHERE id>$1",
+			args:  Args{1},
+		},
+		{
+			name:  "in",
+			where: Where(In(ColumnId, []int64{1, 2, 3})),
+			sql:   "WHERE id = ANY($1)",
+			args:  Args{[]int64{1, 2, 3}},
+		},
+		{
+			name:  "in with empty slice is skipped",
+			where: Where(In(ColumnId, []int64{}), Eq(ColumnSongName, "a")),
+			sql:   "WHERE song_name=$1",
+			args:  Args{"a"},
+		},
+		{
+			name:  "in with nil slice is skipped",
+			where: Where(In(ColumnId, []int64(nil))),
+			sql:   "",
+			args:  nil,
+		},
+		{
+			name:  "prefix",
+			where: Where(Prefix(ColumnSongName, "Super")),
+			sql:   "WHERE song_name ILIKE $1",
+			args:  Args{"Super%"},
+		},
+		{
+			name:  "contains",
+			where: Where(Contains(ColumnText, "black hole")),
+			sql:   "WHERE song_text ILIKE $1",
+			args:  Args{"%black hole%"},
+		},
+		{
+			name:  "empty prefix and contains are skipped",
+			where: Where(Prefix(ColumnSongName, ""), Contains(ColumnText, "")),
+			sql:   "",
+			args:  nil,
+		},
+		{
+			name:  "similar",
+			where: Where(Similar(ColumnSongName, "Supermasive")),
+			sql:   "WHERE song_name % $1",
+			args:  Args{"Supermasive"},
+		},
+		{
+			name:  "prefix escapes like wildcards",
+			where: Where(Prefix(ColumnSongName, `100%_\`)),
+			sql:   "WHERE song_name ILIKE $1",
+			args:  Args{`100\%\_\\%`},
+		},
+		{
+			name:  "contains escapes like wildcards",
+			where: Where(Contains(ColumnText, `a_b%c\d`)),
+			sql:   "WHERE song_text ILIKE $1",
+			args:  Args{`%a\_b\%c\\d%`},
+		},
+		{
+			name:  "group name eq",
+			where: Where(Eq(ColumnGroupName, "Muse")),
+			sql:   "WHERE artist_id=(SELECT id FROM artists WHERE normalized_name=lower(btrim($1)))",
+			args:  Args{"Muse"},
+		},
+		{
+			name:  "group name prefix",
+			where: Where(Prefix(ColumnGroupName, "Mu_")),
+			sql:   "WHERE artist_id IN (SELECT id FROM artists WHERE name ILIKE $1)",
+			args:  Args{`Mu\_%`},
+		},
+		{
+			name:  "group name contains",
+			where: Where(Contains(ColumnGroupName, "us")),
+			sql:   "WHERE artist_id IN (SELECT id FROM artists WHERE name ILIKE $1)",
+			args:  Args{"%us%"},
+		},
+		{
+			name:  "group name similar",
+			where: Where(Similar(ColumnGroupName, "Mose")),
+			sql:   "WHERE artist_id IN (SELECT id FROM artists WHERE name % $1)",
+			args:  Args{"Mose"},
+		},
+		{
+			name:  "group name in",
+			where: Where(In(ColumnGroupName, []string{"Muse", "Queen"})),
+			sql:   "WHERE artist_id IN (SELECT id FROM artists WHERE name = ANY($1))",
+			args:  Args{[]string{"Muse", "Queen"}},
+		},
+		{
+			name:  "empty group name is skipped",
+			where: Where(Eq(ColumnGroupName, ""), Similar(ColumnGroupName, "")),
+			sql:   "",
+			args:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Args
+			sql := tt.where.Build(&args)
+			if sql != tt.sql {
+				t.Errorf("sql = %q, want %q", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestSetBuilder(t *testing.T) {
+	date := time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		set  *SetBuilder
+		sql  string
+		args Args
+	}{
+		{
+			name: "no assignments",
+			set:  Set(),
+			sql:  "",
+			args: nil,
+		},
+		{
+			name: "single assignment",
+			set:  Set().Value(ColumnSongName, "Uprising"),
+			sql:  "SET song_name=$1",
+			args: Args{"Uprising"},
+		},
+		{
+			name: "assignments keep their order",
+			set:  Set().Value(ColumnText, "text").Value(ColumnReleaseDate, date).Value(ColumnLink, "https://example.com"),
+			sql:  "SET song_text=$1, release_date=$2, link=$3",
+			args: Args{"text", date, "https://example.com"},
+		},
+		{
+			name: "empty values are assigned",
+			set:  Set().Value(ColumnText, "").Value(ColumnReleaseDate, time.Time{}),
+			sql:  "SET song_text=$1, release_date=$2",
+			args: Args{"", time.Time{}},
+		},
+		{
+			name: "nil value is assigned",
+			set:  Set().Value(ColumnReleaseDate, nil),
+			sql:  "SET release_date=$1",
+			args: Args{nil},
+		},
+		{
+			name: "repeated value replaces the previous one in place",
+			set:  Set().Value(ColumnSongName, "a").Value(ColumnLink, "l").Value(ColumnSongName, "b"),
+			sql:  "SET song_name=$1, link=$2",
+			args: Args{"b", "l"},
+		},
+		{
+			name: "group name",
+			set:  Set().Value(ColumnGroupName, "Muse"),
+			sql:  "SET artist_id=(SELECT id FROM artists WHERE normalized_name=lower(btrim($1)))",
+			args: Args{"Muse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Args
+			sql := tt.set.Build(&args)
+			if sql != tt.sql {
+				t.Errorf("sql = %q, want %q", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestSetBuilderLookup(t *testing.T) {
+	set := Set().Value(ColumnSongName, "a").Value(ColumnSongName, "b").Value(ColumnLink, nil)
+
+	if value, ok := set.Lookup(ColumnSongName); !ok || value != "b" {
+		t.Errorf("Lookup(song_name) = %v, %v, want b, true", value, ok)
+	}
+	if value, ok := set.Lookup(ColumnLink); !ok || value != nil {
+		t.Errorf("Lookup(link) = %v, %v, want <nil>, true", value, ok)
+	}
+	if _, ok := set.Lookup(ColumnText); ok {
+		t.Error("Lookup(song_text) found unassigned column")
+	}
+	if set.Empty() || !Set().Empty() {
+		t.Error("Empty does not match assignments")
+	}
+}
+
+func TestSharedArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		set   *SetBuilder
+		where *WhereBuilder
+		sql   string
+		args  Args
+	}{
+		{
+			name:  "where placeholders continue set placeholders",
+			set:   Set().Value(ColumnSongName, "b").Value(ColumnLink, ""),
+			where: Where(Eq(ColumnId, int64(7)), Eq(ColumnGroupName, "Muse")),
+			sql:   "UPDATE songs SET song_name=$1, link=$2 WHERE id=$3 AND artist_id=(SELECT id FROM artists WHERE normalized_name=lower(btrim($4)))",
+			args:  Args{"b", "", int64(7), "Muse"},
+		},
+		{
+			name:  "skipped conditions do not take placeholders",
+			set:   Set().Value(ColumnText, "t"),
+			where: Where(Eq(ColumnSongName, ""), Or(Eq(ColumnLink, ""), Gt(ColumnId, 5)), In(ColumnId, []int64{})),
+			sql:   "UPDATE songs SET song_text=$1 WHERE id>$2",
+			args:  Args{"t", 5},
+		},
+		{
+			name:  "empty where",
+			set:   Set().Value(ColumnText, "t"),
+			where: Where(),
+			sql:   "UPDATE songs SET song_text=$1 ",
+			args:  Args{"t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Args
+			sql := "UPDATE songs " + tt.set.Build(&args) + " " + tt.where.Build(&args)
+			if sql != tt.sql {
+				t.Errorf("sql = %q, want %q", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestArgsAdd(t *testing.T) {
+	var args Args
+	for i, want := range []string{"$1", "$2", "$3"} {
+		if got := args.Add(i); got != want {
+			t.Errorf("Add(%d) = %s, want %s", i, got, want)
+		}
+	}
+	if !reflect.DeepEqual(args, Args{0, 1, 2}) {
+		t.Errorf("args = %#v", args)
+	}
+}
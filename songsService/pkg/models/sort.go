@@ -0,0 +1,163 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidSort = errors.New("invalid sort parameter")
+
+// Order is an element of ORDER BY clause.
+type Order struct {
+	Column Column
+	Desc   bool
+}
+
+var sortableColumns = map[Column]bool{
+	ColumnId:          true,
+	ColumnGroupName:   true,
+	ColumnSongName:    true,
+	ColumnReleaseDate: true,
+}
+
+// DefaultSort orders songs by id.
+var DefaultSort = []Order{{Column: ColumnId}}
+
+// ParseSort parses comma-separated list of columns like "release_date,-song_name", "-" prefix means descending order.
+// Id is always appended as the last key if it is missing, so the order is total and keyset pagination is stable.
+func ParseSort(sort string) ([]Order, error) {
+	if sort == "" {
+		return DefaultSort, nil
+	}
+
+	orders := make([]Order, 0, 4)
+	seen := make(map[Column]bool, 4)
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+
+		column, err := ParseColumn(strings.TrimPrefix(field, "-"))
+		if err != nil || !sortableColumns[column] || seen[column] {
+			return nil, ErrInvalidSort
+		}
+		seen[column] = true
+		orders = append(orders, Order{Column: column, Desc: desc})
+	}
+
+	if !seen[ColumnId] {
+		orders = append(orders, Order{Column: ColumnId})
+	}
+
+	return orders, nil
+}
+
+// FormatSort is the inverse of ParseSort.
+func FormatSort(orders []Order) string {
+	fields := make([]string, 0, len(orders))
+	for _, order := range orders {
+		if order.Desc {
+			fields = append(fields, "-"+string(order.Column))
+			continue
+		}
+		fields = append(fields, string(order.Column))
+	}
+	return strings.Join(fields, ",")
+}
+
+// OrderBy returns ORDER BY clause, backward reverses every key, it is used to fetch the page before a cursor.
+func OrderBy(orders []Order, backward bool) string {
+	keys := make([]string, 0, len(orders))
+	for _, order := range orders {
+		direction := " ASC"
+		if order.Desc != backward {
+			direction = " DESC"
+		}
+		keys = append(keys, sortKey(order.Column)+direction)
+	}
+	return "ORDER BY " + strings.Join(keys, ", ")
+}
+
+// SortValue returns value of the song column in the form used by cursors and After.
+func SortValue(song *SongDTO, column Column) string {
+	switch column {
+	case ColumnGroupName:
+		return song.GroupName
+	case ColumnSongName:
+		return song.SongName
+	case ColumnReleaseDate:
+		if song.ReleaseDate.IsZero() {
+			return negativeInfinity
+		}
+		return song.ReleaseDate.Format(dateLayout)
+	default:
+		return ""
+	}
+}
+
+// After matches rows going after the row with the given id and sort values in the given order,
+// or before it when backward. Values are taken from SortValue for every order except id.
+func After(orders []Order, id int64, values []string, backward bool) Condition {
+	return keyset{orders, id, values, backward}
+}
+
+const (
+	dateLayout       = "2006-01-02"
+	negativeInfinity = "-infinity"
+)
+
+type keyset struct {
+	orders   []Order
+	id       int64
+	values   []string
+	backward bool
+}
+
+// build expands row comparison for mixed sort directions:
+// (k1 > v1) OR (k1 = v1 AND k2 < v2) OR (k1 = v1 AND k2 = v2 AND id > v3).
+func (k keyset) build(args *Args) string {
+	placeholders := make([]string, len(k.orders))
+	valueIndex := 0
+	for i, order := range k.orders {
+		switch {
+		case order.Column == ColumnId:
+			placeholders[i] = args.Add(k.id)
+		case valueIndex < len(k.values):
+			placeholders[i] = args.Add(k.values[valueIndex]) + sortCast(order.Column)
+			valueIndex++
+		default:
+			placeholders[i] = "NULL"
+		}
+	}
+
+	alternatives := make([]string, 0, len(k.orders))
+	for i, order := range k.orders {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, sortKey(k.orders[j].Column)+"="+placeholders[j])
+		}
+		op := ">"
+		if order.Desc != k.backward {
+			op = "<"
+		}
+		parts = append(parts, sortKey(order.Column)+op+placeholders[i])
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// sortKey returns expression the column is ordered by, songs without release date go first.
+func sortKey(column Column) string {
+	if column == ColumnReleaseDate {
+		return "COALESCE(release_date, '" + negativeInfinity + "'::date)"
+	}
+	return string(column)
+}
+
+// sortCast converts textual cursor value to the column type.
+func sortCast(column Column) string {
+	if column == ColumnReleaseDate {
+		return "::text::date"
+	}
+	return ""
+}